@@ -38,16 +38,17 @@ func (self Direction) String() string {
 	return res
 }
 
-var dirDeltas map[Direction]image.Point = map[Direction]image.Point{
-	N: {0, -1},
-	E: {1, 0},
-	S: {0, 1},
-	W: {-1, 0},
-}
-
 func (self Direction) Delta() (delta image.Point, error os.Error) {
-	delta, ok := dirDeltas[self]
-	if !ok {
+	switch self {
+	case N:
+		delta = image.Pt(0, -1)
+	case E:
+		delta = image.Pt(1, 0)
+	case S:
+		delta = image.Pt(0, 1)
+	case W:
+		delta = image.Pt(-1, 0)
+	default:
 		error = os.NewError("Unable to fetch delta of a composite direction " +
 			self.String())
 	}
@@ -115,10 +116,15 @@ type Board interface {
 	Entrance() *image.Point
 	Exit() *image.Point
 	Walk(solve bool) ([][]bool, os.Error)
+	Solve() (Path, os.Error)
+	ShortestPath(from, to image.Point) (Path, os.Error)
+	Distances(source image.Point, costs [][]int) (*DistanceMap, os.Error)
 	String() string
 	PrettyString() string
 	Validate() bool
 	Complexity() int
+	DeadEnds() int
+	Loops() int
 }
 
 func New(width, height int) Board {
@@ -196,104 +202,81 @@ func (self *boardImpl) String() string {
 
 func (self *boardImpl) PrettyString() string {
 	var buf bytes.Buffer
-	for y, row := range self.fields {
-		for _, field := range row {
-			if field.Direction()&N != 0 {
-				buf.WriteString("+  ")
-			} else {
-				buf.WriteString("+--")
-			}
+	WritePrettyRows(&buf, RowsOf(self))
+	return buf.String()
+}
+
+// Walk marks the fields reachable from the entrance or, if solve is true,
+// the fields of a path from the entrance to the exit. Fields are kept on an
+// explicit stack rather than walked recursively, so that long corridors of
+// huge boards don't exhaust the goroutine stack.
+func (self *boardImpl) Walk(solve bool) (visitMatrix [][]bool, error os.Error) {
+	width, height := self.Width(), self.Height()
+	boardRectangle := image.Rect(0, 0, width, height)
+	entrance, exit := *self.Entrance(), *self.Exit()
+	visitMatrix = newMatrix(self)
+	var cameFrom [][]Direction
+	if solve {
+		cameFrom = make([][]Direction, height)
+		for y := range cameFrom {
+			cameFrom[y] = make([]Direction, width)
 		}
-		buf.WriteString("+\n")
+	}
 
-		for x, field := range row {
-			dir := field.Direction()
-			if dir&W != 0 {
-				buf.WriteString(" ")
-			} else {
-				buf.WriteString("|")
+	exitReached := false
+	stack := []int{entrance.Y*width + entrance.X}
+	visitMatrix[entrance.Y][entrance.X] = true
+	for len(stack) > 0 {
+		last := len(stack) - 1
+		p := image.Pt(stack[last]%width, stack[last]/width)
+		stack = stack[:last]
+		if p.Eq(exit) {
+			exitReached = true
+		}
+		fieldDir := self.At(p.X, p.Y).Direction()
+		for dir := minDirection; dir <= maxDirection; dir <<= 1 {
+			if fieldDir&dir == 0 {
+				continue
 			}
-			point := image.Pt(x, y)
-			if point.Eq(*self.Entrance()) {
-				buf.WriteString("*")
-			} else {
-				buf.WriteString(" ")
+			delta, _ := dir.Delta()
+			p2 := p.Add(delta)
+			if !p2.In(boardRectangle) {
+				if !p.Eq(entrance) && !p.Eq(exit) {
+					return visitMatrix, os.NewError(
+						"Falling out of the board into " + p2.String())
+				}
+				continue
 			}
-			if point.Eq(*self.Exit()) {
-				buf.WriteString("x")
-			} else {
-				buf.WriteString(" ")
+			if visitMatrix[p2.Y][p2.X] {
+				continue
 			}
-			/*switch {
-			case point.Eq(*self.Entrance()) && point.Eq(*self.Exit()):
-				buf.WriteString("X")
-			case point.Eq(*self.Entrance()):
-				buf.WriteString("*")
-			case point.Eq(*self.Exit()):
-				buf.WriteString("x")
-			default:
-				buf.WriteString(" ")
-			}*/
-		}
-
-		if row[len(row)-1].Direction()&E != 0 {
-			buf.WriteString(" \n")
-		} else {
-			buf.WriteString("|\n")
+			visitMatrix[p2.Y][p2.X] = true
+			if solve {
+				cameFrom[p2.Y][p2.X] = dir.Opposite()
+			}
+			stack = append(stack, p2.Y*width+p2.X)
 		}
 	}
 
-	for _, field := range self.fields[len(self.fields)-1] {
-		if field.Direction()&S != 0 {
-			buf.WriteString("+  ")
-		} else {
-			buf.WriteString("+--")
+	if solve {
+		visitMatrix = newMatrix(self)
+		if exitReached {
+			for _, p := range tracePath(cameFrom, entrance, exit) {
+				visitMatrix[p.Y][p.X] = true
+			}
 		}
 	}
-	buf.WriteString("+\n")
-	return buf.String()
-}
-
-func (self *boardImpl) Walk(solve bool) (visitMatrix [][]bool, error os.Error) {
-	visitMatrix = make([][]bool, self.Height())
-	for i := range visitMatrix {
-		visitMatrix[i] = make([]bool, self.Width())
-	}
-	_, error = self.walkInternal(visitMatrix, *self.Entrance(), solve)
 	return
 }
 
-func (self *boardImpl) walkInternal(visitMatrix [][]bool, p image.Point, solve bool) (exitReached bool, error os.Error) {
-	if visitMatrix[p.Y][p.X] {
-		return false, nil
-	}
-	visitMatrix[p.Y][p.X] = true
-	boardRectangle := image.Rect(0, 0, self.Width(), self.Height())
-	directions := self.At(p.X, p.Y).Direction().Decompose()
-	for _, dir := range directions {
-		delta, error := dir.Delta()
-		if error != nil {
-			return false, error
-		}
-		p2 := p.Add(delta)
-		if p2.In(boardRectangle) {
-			pathToExit, error := self.walkInternal(visitMatrix, p2, solve)
-			exitReached = exitReached || pathToExit
-			if error != nil {
-				return false, error
-			}
-		} else if !p.Eq(*self.Entrance()) && !p.Eq(*self.Exit()) {
-			return false,
-				os.NewError("Falling out of the board into " + p2.String())
-		}
+// newMatrix returns a matrix of the size of the board with all the fields
+// unmarked.
+func newMatrix(b Board) [][]bool {
+	matrix := make([][]bool, b.Height())
+	for i := range matrix {
+		matrix[i] = make([]bool, b.Width())
 	}
-	if p.Eq(*self.Exit()) {
-		exitReached = true
-	}
-	if solve && !exitReached {
-		visitMatrix[p.Y][p.X] = false
-	}
-	return
+	return matrix
 }
 
 func (self *boardImpl) Validate() bool {
@@ -319,6 +302,8 @@ func (self *boardImpl) Validate() bool {
 	return true
 }
 
+// Complexity counts the crossroads of the board, as well as its loops, each
+// of which gives one more way to go astray.
 func (self *boardImpl) Complexity() int {
 	complexity := 0
 	for _, row := range self.fields {
@@ -328,5 +313,55 @@ func (self *boardImpl) Complexity() int {
 			}
 		}
 	}
-	return complexity
+	return complexity + self.Loops()
+}
+
+func (self *boardImpl) DeadEnds() int {
+	deadEnds := 0
+	for _, row := range self.fields {
+		for _, field := range row {
+			if len(field.Direction().Decompose()) == 1 {
+				deadEnds++
+			}
+		}
+	}
+	return deadEnds
+}
+
+// Loops returns the number of independent loops of the board, i.e. the
+// number of passages that would have to be walled up to make it a tree.
+func (self *boardImpl) Loops() int {
+	width := self.Width()
+	sets := make([]int, width*self.Height())
+	for i := range sets {
+		sets[i] = i
+	}
+	find := func(i int) int {
+		for sets[i] != i {
+			sets[i] = sets[sets[i]]
+			i = sets[i]
+		}
+		return i
+	}
+	loops := 0
+	join := func(i, j int) {
+		i, j = find(i), find(j)
+		if i == j {
+			loops++
+		} else {
+			sets[j] = i
+		}
+	}
+	for y, row := range self.fields {
+		for x, field := range row {
+			dir := field.Direction()
+			if dir&E != 0 && x+1 < width {
+				join(y*width+x, y*width+x+1)
+			}
+			if dir&S != 0 && y+1 < len(self.fields) {
+				join(y*width+x, (y+1)*width+x)
+			}
+		}
+	}
+	return loops
 }
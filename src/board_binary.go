@@ -0,0 +1,257 @@
+package board
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"image"
+	"io"
+	"os"
+)
+
+// The binary format stores only the eastern and southern passages of every
+// field, as the northern and western ones follow from those of the
+// neighbours. It consists of:
+//
+//	"MAZE", the version byte and the width, height, entrance and exit as
+//	big-endian 32 bit integers;
+//	the northern passages of the first row, one bit per field;
+//	for every row, the western passage of its first field followed by the
+//	eastern and southern passages of each field;
+//	the CRC-32 of all the above.
+//
+// Bits are packed from the lowest one, and the passages of the first row and
+// of every row start at a new byte, so that boards can be read and written a
+// row at a time. This makes about two bits per field.
+var binaryMagic = []byte("MAZE")
+
+const binaryVersion = 1
+
+// maxBinarySize limits the width and height of boards read, so that corrupt
+// input can't make the reader allocate huge rows.
+const maxBinarySize = 1 << 20
+
+func WriteBinary(w io.Writer, b Board) os.Error {
+	return WriteBinaryRows(w, RowsOf(b))
+}
+
+func WriteBinaryRows(w io.Writer, rows Rows) os.Error {
+	out := bufio.NewWriter(w)
+	crc := crc32.NewIEEE()
+	data := io.MultiWriter(out, crc)
+	width, height := rows.Width(), rows.Height()
+	header := append([]byte{}, binaryMagic...)
+	header = append(header, binaryVersion)
+	for _, value := range []int{width, height,
+		rows.Entrance().X, rows.Entrance().Y, rows.Exit().X, rows.Exit().Y} {
+		header = appendUint32(header, uint32(value))
+	}
+	if _, error := data.Write(header); error != nil {
+		return error
+	}
+	bits := make([]byte, (2*width+8)/8)
+	for y := 0; y < height; y++ {
+		row, error := rows.NextRow()
+		if error != nil {
+			return error
+		}
+		if y == 0 {
+			top := bits[:(width+7)/8]
+			clearBits(top)
+			for x, field := range row {
+				setBit(top, x, field.Direction()&N != 0)
+			}
+			if _, error = data.Write(top); error != nil {
+				return error
+			}
+		}
+		clearBits(bits)
+		setBit(bits, 0, row[0].Direction()&W != 0)
+		for x, field := range row {
+			setBit(bits, 2*x+1, field.Direction()&E != 0)
+			setBit(bits, 2*x+2, field.Direction()&S != 0)
+		}
+		if _, error = data.Write(bits); error != nil {
+			return error
+		}
+	}
+	if _, error := out.Write(appendUint32(nil, crc.Sum32())); error != nil {
+		return error
+	}
+	return out.Flush()
+}
+
+func appendUint32(data []byte, value uint32) []byte {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], value)
+	return append(data, buf[:]...)
+}
+
+func clearBits(bits []byte) {
+	for i := range bits {
+		bits[i] = 0
+	}
+}
+
+func setBit(bits []byte, i int, value bool) {
+	if value {
+		bits[i/8] |= 1 << uint(i%8)
+	}
+}
+
+func getBit(bits []byte, i int) bool {
+	return bits[i/8]&(1<<uint(i%8)) != 0
+}
+
+// checkPadding tells whether the bits past the given number are all zero.
+func checkPadding(bits []byte, used int) bool {
+	for i := used; i < 8*len(bits); i++ {
+		if getBit(bits, i) {
+			return false
+		}
+	}
+	return true
+}
+
+type binaryRows struct {
+	r              io.Reader
+	crc            hash.Hash32
+	width, height  int
+	entrance, exit image.Point
+	y              int
+	previous       []Field
+	top            []byte
+	done           bool
+}
+
+// NewBinaryRows reads the header of a board in the binary format, returning
+// its rows to be read one at a time. The checksum is verified after the last
+// row, so that NextRow returns os.EOF only if the whole board is intact.
+// Nothing past the checksum is read, so that boards can follow one another
+// in a stream.
+func NewBinaryRows(r io.Reader) (Rows, os.Error) {
+	self := &binaryRows{r: r, crc: crc32.NewIEEE()}
+	header, error := self.read(len(binaryMagic) + 1 + 6*4)
+	if error != nil {
+		return nil, error
+	}
+	if string(header[:len(binaryMagic)]) != string(binaryMagic) {
+		return nil, os.NewError("Not a board in the binary format")
+	}
+	if version := header[len(binaryMagic)]; version != binaryVersion {
+		return nil, fmt.Errorf("Unsupported version %d of the binary format", version)
+	}
+	values := make([]int, 6)
+	for i := range values {
+		offset := len(binaryMagic) + 1 + 4*i
+		value := binary.BigEndian.Uint32(header[offset : offset+4])
+		if value > maxBinarySize {
+			return nil, fmt.Errorf("Invalid board dimension or position %d", value)
+		}
+		values[i] = int(value)
+	}
+	self.width, self.height = values[0], values[1]
+	self.entrance, self.exit = image.Pt(values[2], values[3]), image.Pt(values[4], values[5])
+	bounds := image.Rect(0, 0, self.width, self.height)
+	if bounds.Empty() {
+		return nil, fmt.Errorf("Invalid board size %dx%d", self.width, self.height)
+	}
+	if !self.entrance.In(bounds) || !self.exit.In(bounds) {
+		return nil, fmt.Errorf("Entrance %v or exit %v lies outside of the board",
+			self.entrance, self.exit)
+	}
+	if self.top, error = self.read((self.width + 7) / 8); error != nil {
+		return nil, error
+	}
+	if !checkPadding(self.top, self.width) {
+		return nil, os.NewError("Corrupt padding of the first row")
+	}
+	return self, nil
+}
+
+// read reads the given number of bytes, adding them to the checksum.
+func (self *binaryRows) read(n int) ([]byte, os.Error) {
+	data := make([]byte, n)
+	if _, error := io.ReadFull(self.r, data); error != nil {
+		if error == os.EOF || error == io.ErrUnexpectedEOF {
+			return nil, os.NewError("Board in the binary format is truncated")
+		}
+		return nil, error
+	}
+	self.crc.Write(data)
+	return data, nil
+}
+
+func (self *binaryRows) Width() int            { return self.width }
+func (self *binaryRows) Height() int           { return self.height }
+func (self *binaryRows) Entrance() image.Point { return self.entrance }
+func (self *binaryRows) Exit() image.Point     { return self.exit }
+
+func (self *binaryRows) NextRow() ([]Field, os.Error) {
+	if self.done {
+		return nil, os.EOF
+	}
+	if self.y == self.height {
+		expected := self.crc.Sum32()
+		checksum, error := self.read(4)
+		if error != nil {
+			return nil, error
+		}
+		if binary.BigEndian.Uint32(checksum) != expected {
+			return nil, os.NewError("Checksum of the board doesn't match")
+		}
+		self.done = true
+		return nil, os.EOF
+	}
+	bits, error := self.read((2*self.width + 8) / 8)
+	if error != nil {
+		return nil, error
+	}
+	if !checkPadding(bits, 2*self.width+1) {
+		return nil, fmt.Errorf("Corrupt padding of row %d", self.y)
+	}
+	row := make([]Field, self.width)
+	for x := range row {
+		var dir Direction
+		if self.y == 0 && getBit(self.top, x) ||
+			self.y > 0 && self.previous[x].Direction()&S != 0 {
+			dir |= N
+		}
+		if x == 0 && getBit(bits, 0) || x > 0 && getBit(bits, 2*x-1) {
+			dir |= W
+		}
+		if getBit(bits, 2*x+1) {
+			dir |= E
+		}
+		if getBit(bits, 2*x+2) {
+			dir |= S
+		}
+		row[x].SetDirection(dir)
+	}
+	self.previous = row
+	self.y++
+	return row, nil
+}
+
+// ReadBinary reads a whole board in the binary format. The board is only
+// built once its checksum has been verified.
+func ReadBinary(r io.Reader) (Board, os.Error) {
+	rows, error := NewBinaryRows(r)
+	if error != nil {
+		return nil, error
+	}
+	fields := make([][]Field, 0)
+	for {
+		row, error := rows.NextRow()
+		if error == os.EOF {
+			break
+		}
+		if error != nil {
+			return nil, error
+		}
+		fields = append(fields, row)
+	}
+	return &boardImpl{fields: fields, entrance: rows.Entrance(), exit: rows.Exit()}, nil
+}
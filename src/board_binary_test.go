@@ -0,0 +1,72 @@
+package board
+
+import (
+	"bytes"
+	"rand"
+	"testing"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	var stream bytes.Buffer
+	boards := make([]Board, 30)
+	for i := range boards {
+		boards[i] = randomBoard(rng, 1+rng.Intn(20), 1+rng.Intn(20))
+		if error := WriteBinary(&stream, boards[i]); error != nil {
+			t.Fatalf("Unable to write a board: %v", error)
+		}
+	}
+	// The boards follow one another in a single stream.
+	for _, b := range boards {
+		read, error := ReadBinary(&stream)
+		if error != nil {
+			t.Fatalf("Unable to read a board: %v", error)
+		}
+		if !boardsEqual(read, b) {
+			t.Fatalf("Board read as\n%s\nexpected\n%s", read.String(), b.String())
+		}
+		if !read.Validate() {
+			t.Errorf("Board read is invalid")
+		}
+	}
+	if stream.Len() != 0 {
+		t.Errorf("%d bytes were left unread", stream.Len())
+	}
+}
+
+func TestBinarySize(t *testing.T) {
+	b := randomBoard(rand.New(rand.NewSource(0)), 200, 100)
+	var buf bytes.Buffer
+	WriteBinary(&buf, b)
+	if bits := float64(8*buf.Len()) / (200 * 100); bits > 2.1 {
+		t.Errorf("Board takes %.2f bits per field", bits)
+	}
+}
+
+// TestReadingCorruptBinary checks that damaged boards are refused rather than
+// read wrong or making the reader crash.
+func TestReadingCorruptBinary(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for i := 0; i < 2000; i++ {
+		var buf bytes.Buffer
+		WriteBinary(&buf, randomBoard(rng, 1+rng.Intn(10), 1+rng.Intn(10)))
+		data := buf.Bytes()
+		switch rng.Intn(3) {
+		case 0:
+			data = data[:rng.Intn(len(data))]
+		case 1:
+			for n := 1 + rng.Intn(3); n > 0; n-- {
+				data[rng.Intn(len(data))] ^= byte(1 + rng.Intn(255))
+			}
+		case 2:
+			for j := range data {
+				data[j] = byte(rng.Intn(256))
+			}
+			copy(data, binaryMagic)
+			data[len(binaryMagic)] = binaryVersion
+		}
+		if b, error := ReadBinary(bytes.NewBuffer(data)); error == nil {
+			t.Fatalf("Corrupt board %v was read as\n%s", data, b.String())
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package board
+
+import (
+	"container/heap"
+	"fmt"
+	"image"
+	"os"
+)
+
+// DistanceMap holds the distances of all fields from a source field.
+// Unreachable fields have a distance of -1.
+type DistanceMap struct {
+	Distances [][]int
+	Farthest  image.Point
+	Max       int
+}
+
+type distanceHeapElement struct {
+	Coords   image.Point
+	Distance int
+}
+
+type distanceHeap []distanceHeapElement
+
+func (self *distanceHeap) Push(x interface{}) {
+	*self = append(*self, x.(distanceHeapElement))
+}
+
+func (self *distanceHeap) Pop() interface{} {
+	last := len(*self) - 1
+	result := (*self)[last]
+	*self = (*self)[:last]
+	return result
+}
+
+func (self distanceHeap) Len() int           { return len(self) }
+func (self distanceHeap) Less(i, j int) bool { return self[i].Distance < self[j].Distance }
+func (self distanceHeap) Swap(i, j int)      { self[i], self[j] = self[j], self[i] }
+
+// Distances computes the distances from the source using Dijkstra's
+// algorithm. If costs is not nil, it holds the cost of entering each field;
+// otherwise, each step costs 1.
+func (self *boardImpl) Distances(source image.Point, costs [][]int) (*DistanceMap, os.Error) {
+	width, height := self.Width(), self.Height()
+	boardRectangle := image.Rect(0, 0, width, height)
+	if !source.In(boardRectangle) {
+		return nil, fmt.Errorf("Source %v is out of the board", source)
+	}
+	if costs != nil && len(costs) != height {
+		return nil, fmt.Errorf("Costs have %d rows, expected %d", len(costs), height)
+	}
+	distances := make([][]int, height)
+	for y := range distances {
+		if costs != nil && len(costs[y]) != width {
+			return nil, fmt.Errorf("Row %d of the costs has %d fields, expected %d",
+				y, len(costs[y]), width)
+		}
+		distances[y] = make([]int, width)
+		for x := range distances[y] {
+			distances[y][x] = -1
+			if costs != nil && costs[y][x] < 0 {
+				return nil, fmt.Errorf("Negative cost of field (%d, %d)", x, y)
+			}
+		}
+	}
+
+	done := newMatrix(self)
+	distances[source.Y][source.X] = 0
+	fieldQueue := &distanceHeap{{source, 0}}
+	for fieldQueue.Len() > 0 {
+		element := heap.Pop(fieldQueue).(distanceHeapElement)
+		p := element.Coords
+		if done[p.Y][p.X] {
+			continue
+		}
+		done[p.Y][p.X] = true
+		fieldDir := self.At(p.X, p.Y).Direction()
+		for dir := minDirection; dir <= maxDirection; dir <<= 1 {
+			if fieldDir&dir == 0 {
+				continue
+			}
+			delta, _ := dir.Delta()
+			p2 := p.Add(delta)
+			if !p2.In(boardRectangle) || done[p2.Y][p2.X] {
+				continue
+			}
+			distance := element.Distance + 1
+			if costs != nil {
+				distance = element.Distance + costs[p2.Y][p2.X]
+			}
+			if d := distances[p2.Y][p2.X]; d < 0 || distance < d {
+				distances[p2.Y][p2.X] = distance
+				heap.Push(fieldQueue, distanceHeapElement{p2, distance})
+			}
+		}
+	}
+
+	result := &DistanceMap{Distances: distances, Farthest: source}
+	for y, row := range distances {
+		for x, distance := range row {
+			if distance > result.Max {
+				result.Max = distance
+				result.Farthest = image.Pt(x, y)
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,116 @@
+package board
+
+import (
+	"image"
+	"testing"
+)
+
+func distancesEqual(d1, d2 [][]int) bool {
+	if len(d1) != len(d2) {
+		return false
+	}
+	for y := range d1 {
+		if len(d1[y]) != len(d2[y]) {
+			return false
+		}
+		for x := range d1[y] {
+			if d1[y][x] != d2[y][x] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+type distanceTest struct {
+	Source    image.Point
+	Costs     [][]int
+	Distances [][]int
+	Farthest  image.Point
+	Max       int
+}
+
+var distanceTests []distanceTest = []distanceTest{
+	{
+		Source: image.Pt(0, 0),
+		Distances: [][]int{
+			{0, 1, 2},
+			{1, 2, 3},
+			{6, 5, 4},
+		},
+		Farthest: image.Pt(0, 2),
+		Max:      6,
+	},
+	{
+		Source: image.Pt(1, 1),
+		Distances: [][]int{
+			{2, 3, 2},
+			{1, 0, 1},
+			{4, 3, 2},
+		},
+		Farthest: image.Pt(0, 2),
+		Max:      4,
+	},
+	{
+		Source: image.Pt(0, 0),
+		Costs: [][]int{
+			{0, 5, 1},
+			{1, 1, 1},
+			{1, 1, 1},
+		},
+		Distances: [][]int{
+			{0, 5, 4},
+			{1, 2, 3},
+			{6, 5, 4},
+		},
+		Farthest: image.Pt(0, 2),
+		Max:      6,
+	},
+}
+
+func TestDistances(t *testing.T) {
+	for i, test := range distanceTests {
+		distanceMap, error := loopBoard.Distances(test.Source, test.Costs)
+		if error != nil {
+			t.Errorf("Unexpected error in test %d: %v", i, error)
+			continue
+		}
+		if !distancesEqual(distanceMap.Distances, test.Distances) {
+			t.Errorf("Distances in test %d are %v, expected %v",
+				i, distanceMap.Distances, test.Distances)
+		}
+		if !distanceMap.Farthest.Eq(test.Farthest) || distanceMap.Max != test.Max {
+			t.Errorf("Farthest field in test %d is %v at %d, expected %v at %d",
+				i, distanceMap.Farthest, distanceMap.Max, test.Farthest, test.Max)
+		}
+	}
+}
+
+func TestDistancesOfUnreachableFields(t *testing.T) {
+	// +-+-+
+	//  *|x
+	// +-+-+
+	board := boardImpl{
+		fields:   [][]Field{{Field(W), Field(E)}},
+		entrance: image.Pt(0, 0),
+		exit:     image.Pt(1, 0),
+	}
+	distanceMap, error := board.Distances(image.Pt(0, 0), nil)
+	if error != nil {
+		t.Fatalf("Unexpected error: %v", error)
+	}
+	if !distancesEqual(distanceMap.Distances, [][]int{{0, -1}}) {
+		t.Errorf("Distances are %v, expected [[0 -1]]", distanceMap.Distances)
+	}
+	if _, error := board.Distances(image.Pt(2, 0), nil); error == nil {
+		t.Errorf("Computed distances from outside of the board")
+	}
+	if _, error := board.Distances(image.Pt(0, 0), [][]int{{1, -1}}); error == nil {
+		t.Errorf("Computed distances with negative costs")
+	}
+	for _, costs := range [][][]int{{}, {{1}}, {{1, 1}, {1, 1}}, {{1, 1, 1}}} {
+		if _, error := board.Distances(image.Pt(0, 0), costs); error == nil {
+			t.Errorf("Computed distances with costs %v of the wrong size", costs)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package board
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"json"
+	"os"
+)
+
+// JSONVersion is the version of the JSON format of Record. Records of later
+// versions are refused, as they may hold boards this code can't represent.
+const JSONVersion = 1
+
+// Record is a board together with the way it was generated, so that it can be
+// stored and exchanged as JSON, for example:
+//
+//	{"version": 1, "width": 2, "height": 1, "fields": [[3, 8]],
+//	 "entrance": {"x": 0, "y": 0}, "exit": {"x": 1, "y": 0},
+//	 "generator": "growing-tree", "seed": 42}
+//
+// Each field is given by its Direction bits.
+type Record struct {
+	Board     Board
+	Generator string
+	Seed      int64
+}
+
+type jsonPoint struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type jsonRecord struct {
+	Version   int       `json:"version"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	Fields    [][]int   `json:"fields"`
+	Entrance  jsonPoint `json:"entrance"`
+	Exit      jsonPoint `json:"exit"`
+	Generator string    `json:"generator"`
+	Seed      int64     `json:"seed"`
+}
+
+func (self *Record) MarshalJSON() ([]byte, os.Error) {
+	b := self.Board
+	record := jsonRecord{
+		Version:   JSONVersion,
+		Width:     b.Width(),
+		Height:    b.Height(),
+		Fields:    make([][]int, b.Height()),
+		Entrance:  jsonPoint{b.Entrance().X, b.Entrance().Y},
+		Exit:      jsonPoint{b.Exit().X, b.Exit().Y},
+		Generator: self.Generator,
+		Seed:      self.Seed,
+	}
+	for y := range record.Fields {
+		record.Fields[y] = make([]int, b.Width())
+		for x := range record.Fields[y] {
+			record.Fields[y][x] = int(b.At(x, y).Direction())
+		}
+	}
+	return json.Marshal(&record)
+}
+
+// UnmarshalJSON reads a record, refusing boards which are malformed or whose
+// fields disagree on the passages between them.
+func (self *Record) UnmarshalJSON(data []byte) os.Error {
+	var record jsonRecord
+	if error := json.Unmarshal(data, &record); error != nil {
+		return error
+	}
+	switch {
+	case record.Version < 1 || record.Version > JSONVersion:
+		return fmt.Errorf("Unsupported version %d of the board format", record.Version)
+	case record.Width < 1 || record.Height < 1:
+		return fmt.Errorf("Invalid board size %dx%d", record.Width, record.Height)
+	case len(record.Fields) != record.Height:
+		return fmt.Errorf("Board has %d rows of fields, expected %d",
+			len(record.Fields), record.Height)
+	}
+	// The size is checked against the fields read before allocating the
+	// board, so that a record can't claim a huge board with only a few fields.
+	for y, row := range record.Fields {
+		if len(row) != record.Width {
+			return fmt.Errorf("Row %d has %d fields, expected %d", y, len(row), record.Width)
+		}
+	}
+	b := New(record.Width, record.Height)
+	for y, row := range record.Fields {
+		for x, dir := range row {
+			if dir < 0 || dir > int(directionMask) {
+				return fmt.Errorf("Field (%d, %d) has invalid directions %d", x, y, dir)
+			}
+			b.At(x, y).SetDirection(Direction(dir))
+		}
+	}
+	bounds := image.Rect(0, 0, record.Width, record.Height)
+	*b.Entrance() = image.Pt(record.Entrance.X, record.Entrance.Y)
+	*b.Exit() = image.Pt(record.Exit.X, record.Exit.Y)
+	if !b.Entrance().In(bounds) || !b.Exit().In(bounds) {
+		return fmt.Errorf("Entrance %v or exit %v lies outside of the board",
+			*b.Entrance(), *b.Exit())
+	}
+	if !b.Validate() {
+		return os.NewError("Fields of the board disagree on the passages between them")
+	}
+	self.Board, self.Generator, self.Seed = b, record.Generator, record.Seed
+	return nil
+}
+
+// WriteJSON writes the record as JSON, followed by a new line.
+func WriteJSON(w io.Writer, record *Record) os.Error {
+	data, error := json.Marshal(record)
+	if error != nil {
+		return error
+	}
+	_, error = w.Write(append(data, '\n'))
+	return error
+}
+
+func ReadJSON(r io.Reader) (*Record, os.Error) {
+	record := &Record{}
+	if error := json.NewDecoder(r).Decode(record); error != nil {
+		return nil, error
+	}
+	return record, nil
+}
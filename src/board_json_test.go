@@ -0,0 +1,55 @@
+package board
+
+import (
+	"bytes"
+	"rand"
+	"strings"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for i := 0; i < 20; i++ {
+		b := randomBoard(rng, 1+rng.Intn(8), 1+rng.Intn(8))
+		var buf bytes.Buffer
+		if error := WriteJSON(&buf, &Record{Board: b, Generator: "random", Seed: int64(i)}); error != nil {
+			t.Fatalf("Unable to write a board: %v", error)
+		}
+		text := buf.String()
+		record, error := ReadJSON(&buf)
+		if error != nil {
+			t.Fatalf("Unable to read a board from %s: %v", text, error)
+		}
+		if !boardsEqual(record.Board, b) || record.Generator != "random" ||
+			record.Seed != int64(i) {
+			t.Fatalf("Board read from %s differs:\n%s", text, record.Board.String())
+		}
+	}
+}
+
+func TestReadingInvalidJSON(t *testing.T) {
+	valid := `{"version": 1, "width": 2, "height": 1, "fields": [[3, 8]],
+		"entrance": {"x": 0, "y": 0}, "exit": {"x": 1, "y": 0},
+		"generator": "growing-tree", "seed": 42}`
+	if record, error := ReadJSON(strings.NewReader(valid)); error != nil {
+		t.Fatalf("Unable to read a valid board: %v", error)
+	} else if record.Board.At(0, 0).Direction() != N|E || record.Seed != 42 {
+		t.Errorf("Board was read as\n%s", record.Board.String())
+	}
+	for _, invalid := range []string{
+		strings.Replace(valid, `"version": 1`, `"version": 2`, 1),
+		strings.Replace(valid, `"version": 1`, `"version": 0`, 1),
+		strings.Replace(valid, `"width": 2`, `"width": 0`, 1),
+		strings.Replace(valid, `"width": 2`, `"width": 1000000000`, 1),
+		strings.Replace(valid, `[[3, 8]]`, `[[3, 0]]`, 1),
+		strings.Replace(valid, `[[3, 8]]`, `[[3]]`, 1),
+		strings.Replace(valid, `[[3, 8]]`, `[[3, 8], [0, 0]]`, 1),
+		strings.Replace(valid, `[[3, 8]]`, `[[3, 24]]`, 1),
+		strings.Replace(valid, `"x": 1`, `"x": 2`, 1),
+		`{"version": 1, "width": "wide"}`,
+	} {
+		if _, error := ReadJSON(strings.NewReader(invalid)); error == nil {
+			t.Errorf("Invalid board was read from %s", invalid)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package board
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"strings"
+)
+
+// ParseError tells where the text of a board is malformed. Lines and columns
+// are counted from 1, columns in characters rather than bytes. Columns point
+// into the text as given, counting the characters of any ANSI escape codes.
+type ParseError struct {
+	Line, Column int
+	Message      string
+}
+
+func (self *ParseError) String() string {
+	return fmt.Sprintf("%d:%d: %s", self.Line, self.Column, self.Message)
+}
+
+// Parse reads a board written by String, PrettyString or WriteBox, telling
+// the format by the width of the lines and by the characters used.
+func Parse(text string) (Board, os.Error) {
+	firstLine := text
+	if end := strings.Index(text, "\n"); end >= 0 {
+		firstLine = text[:end]
+	}
+	switch {
+	case !isASCII(text):
+		return ParseBox(text)
+	case len(firstLine)%3 == 1:
+		return ParsePretty(text)
+	}
+	return ParseString(text)
+}
+
+func isASCII(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// textParser holds the text split into lines of characters. If ANSI escape
+// codes are stripped from the lines, columns maps the characters left, and
+// the end of the line, to their columns in the text given.
+type textParser struct {
+	lines   [][]string
+	columns [][]int
+}
+
+func newTextParser(text string, stripANSI bool) *textParser {
+	text = strings.TrimRight(text, "\n")
+	self := &textParser{}
+	for _, line := range strings.Split(text, "\n") {
+		chars := strings.Split(strings.TrimRight(line, "\r"), "")
+		kept := make([]string, 0, len(chars))
+		columns := make([]int, 0, len(chars)+1)
+		end := 0
+		for i := 0; i < len(chars); i++ {
+			if stripANSI && chars[i] == "\x1b" && i+1 < len(chars) && chars[i+1] == "[" {
+				for i < len(chars) && chars[i] != "m" {
+					i++
+				}
+				continue
+			}
+			kept, columns = append(kept, chars[i]), append(columns, i)
+			end = i + 1
+		}
+		self.lines = append(self.lines, kept)
+		self.columns = append(self.columns, append(columns, end))
+	}
+	return self
+}
+
+func (self *textParser) errorf(line, column int, format string, args ...interface{}) os.Error {
+	if line < len(self.columns) && column < len(self.columns[line]) {
+		column = self.columns[line][column]
+	}
+	return &ParseError{line + 1, column + 1, fmt.Sprintf(format, args...)}
+}
+
+// size checks that there is a multiple of the field height plus the given
+// number of extra lines, all of the same width, being a multiple of the
+// field width plus the given number of extra characters, and returns the
+// size of the board.
+func (self *textParser) size(fieldWidth, extraWidth, fieldHeight, extraHeight int) (int, int, os.Error) {
+	lines := len(self.lines)
+	if lines < fieldHeight+extraHeight || (lines-extraHeight)%fieldHeight != 0 {
+		return 0, 0, self.errorf(lines-1, 0, "Board has %d lines", lines)
+	}
+	columns := len(self.lines[0])
+	if columns < fieldWidth+extraWidth || (columns-extraWidth)%fieldWidth != 0 {
+		return 0, 0, self.errorf(0, columns, "Line is %d characters wide", columns)
+	}
+	for y, line := range self.lines {
+		if len(line) != columns {
+			return 0, 0, self.errorf(y, min(len(line), columns),
+				"Line is %d characters wide, expected %d", len(line), columns)
+		}
+	}
+	return (columns - extraWidth) / fieldWidth, (lines - extraHeight) / fieldHeight, nil
+}
+
+// match returns the index of the alternative found at the given position.
+// All the alternatives must be of the same length. The error points at the
+// first character which doesn't match any of them.
+func (self *textParser) match(line, column int, alternatives ...string) (int, os.Error) {
+	found := self.lines[line][column : column+len(strings.Split(alternatives[0], ""))]
+	matched := 0
+	for i, alternative := range alternatives {
+		chars := strings.Split(alternative, "")
+		n := 0
+		for n < len(chars) && chars[n] == found[n] {
+			n++
+		}
+		if n == len(chars) {
+			return i, nil
+		}
+		matched = max(matched, n)
+	}
+	return 0, self.errorf(line, column+matched, "Expected %s, found %q",
+		quoteAll(alternatives), strings.Join(found, ""))
+}
+
+func quoteAll(alternatives []string) string {
+	quoted := make([]string, len(alternatives))
+	for i, alternative := range alternatives {
+		quoted[i] = fmt.Sprintf("%q", alternative)
+	}
+	return strings.Join(quoted, " or ")
+}
+
+// markers records the entrance and exit of the board being parsed.
+type markers struct {
+	parser               *textParser
+	entrance, exit       image.Point
+	hasEntrance, hasExit bool
+}
+
+func (self *markers) setEntrance(p image.Point, line, column int) os.Error {
+	if self.hasEntrance {
+		return self.parser.errorf(line, column, "Second entrance, the first one is at %v", self.entrance)
+	}
+	self.entrance, self.hasEntrance = p, true
+	return nil
+}
+
+func (self *markers) setExit(p image.Point, line, column int) os.Error {
+	if self.hasExit {
+		return self.parser.errorf(line, column, "Second exit, the first one is at %v", self.exit)
+	}
+	self.exit, self.hasExit = p, true
+	return nil
+}
+
+func (self *markers) apply(b Board) os.Error {
+	last := len(self.parser.lines) - 1
+	switch {
+	case !self.hasEntrance:
+		return self.parser.errorf(last, len(self.parser.lines[last]), "Board has no entrance")
+	case !self.hasExit:
+		return self.parser.errorf(last, len(self.parser.lines[last]), "Board has no exit")
+	}
+	*b.Entrance(), *b.Exit() = self.entrance, self.exit
+	return nil
+}
+
+// ParseString reads a board in the format of Board.String, where every field
+// is a block of three by three characters.
+func ParseString(text string) (Board, os.Error) {
+	parser := newTextParser(text, false)
+	width, height, error := parser.size(3, 0, 3, 0)
+	if error != nil {
+		return nil, error
+	}
+	b := New(width, height)
+	found := &markers{parser: parser}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			line, column := 3*y, 3*x
+			var dir Direction
+			for _, side := range []struct {
+				Line         int
+				Open, Closed string
+				Dir          Direction
+			}{{line, "+ +", "+-+", N}, {line + 2, "+ +", "+-+", S}} {
+				i, error := parser.match(side.Line, column, side.Open, side.Closed)
+				if error != nil {
+					return nil, error
+				}
+				if i == 0 {
+					dir |= side.Dir
+				}
+			}
+			for _, side := range []struct {
+				Column int
+				Dir    Direction
+			}{{column, W}, {column + 2, E}} {
+				i, error := parser.match(line+1, side.Column, " ", "|")
+				if error != nil {
+					return nil, error
+				}
+				if i == 0 {
+					dir |= side.Dir
+				}
+			}
+			b.At(x, y).SetDirection(dir)
+
+			marker, error := parser.match(line+1, column+1, " ", "*", "x", "X")
+			if error != nil {
+				return nil, error
+			}
+			p := image.Pt(x, y)
+			if marker == 1 || marker == 3 {
+				if error = found.setEntrance(p, line+1, column+1); error != nil {
+					return nil, error
+				}
+			}
+			if marker == 2 || marker == 3 {
+				if error = found.setExit(p, line+1, column+1); error != nil {
+					return nil, error
+				}
+			}
+		}
+	}
+	if error = found.apply(b); error != nil {
+		return nil, error
+	}
+	return b, nil
+}
+
+// ParsePretty reads a board in the format of Board.PrettyString, where the
+// neighbouring fields share the walls between them.
+func ParsePretty(text string) (Board, os.Error) {
+	parser := newTextParser(text, false)
+	width, height, error := parser.size(3, 1, 2, 1)
+	if error != nil {
+		return nil, error
+	}
+	b := New(width, height)
+	found := &markers{parser: parser}
+	for y := 0; y <= height; y++ {
+		for x := 0; x < width; x++ {
+			if _, error := parser.match(2*y, 3*x, "+"); error != nil {
+				return nil, error
+			}
+			i, error := parser.match(2*y, 3*x+1, "  ", "--")
+			if error != nil {
+				return nil, error
+			}
+			if i == 0 && y < height {
+				b.At(x, y).AddDirection(N)
+			}
+			if i == 0 && y > 0 {
+				b.At(x, y-1).AddDirection(S)
+			}
+		}
+		if _, error := parser.match(2*y, 3*width, "+"); error != nil {
+			return nil, error
+		}
+	}
+	for y := 0; y < height; y++ {
+		line := 2*y + 1
+		for x := 0; x <= width; x++ {
+			i, error := parser.match(line, 3*x, " ", "|")
+			if error != nil {
+				return nil, error
+			}
+			if i == 0 && x < width {
+				b.At(x, y).AddDirection(W)
+			}
+			if i == 0 && x > 0 {
+				b.At(x-1, y).AddDirection(E)
+			}
+			if x == width {
+				break
+			}
+			p := image.Pt(x, y)
+			if i, error = parser.match(line, 3*x+1, " ", "*"); error != nil {
+				return nil, error
+			}
+			if i == 1 {
+				if error = found.setEntrance(p, line, 3*x+1); error != nil {
+					return nil, error
+				}
+			}
+			if i, error = parser.match(line, 3*x+2, " ", "x"); error != nil {
+				return nil, error
+			}
+			if i == 1 {
+				if error = found.setExit(p, line, 3*x+2); error != nil {
+					return nil, error
+				}
+			}
+		}
+	}
+	if error = found.apply(b); error != nil {
+		return nil, error
+	}
+	return b, nil
+}
+
+// ParseBox reads a board drawn by WriteBox, with or without colours. The
+// path drawn is ignored.
+func ParseBox(text string) (Board, os.Error) {
+	parser := newTextParser(text, true)
+	width, height, error := parser.size(4, 1, 2, 1)
+	if error != nil {
+		return nil, error
+	}
+	b := New(width, height)
+	found := &markers{parser: parser}
+	for y := 0; y <= height; y++ {
+		for x := 0; x < width; x++ {
+			if _, error := parser.match(2*y, 4*x, boxJunctions...); error != nil {
+				return nil, error
+			}
+			i, error := parser.match(2*y, 4*x+1, "   ", " · ", "───")
+			if error != nil {
+				return nil, error
+			}
+			if i < 2 && y < height {
+				b.At(x, y).AddDirection(N)
+			}
+			if i < 2 && y > 0 {
+				b.At(x, y-1).AddDirection(S)
+			}
+		}
+		if _, error := parser.match(2*y, 4*width, boxJunctions...); error != nil {
+			return nil, error
+		}
+	}
+	for y := 0; y < height; y++ {
+		line := 2*y + 1
+		for x := 0; x <= width; x++ {
+			i, error := parser.match(line, 4*x, " ", "·", "│")
+			if error != nil {
+				return nil, error
+			}
+			if i < 2 && x < width {
+				b.At(x, y).AddDirection(W)
+			}
+			if i < 2 && x > 0 {
+				b.At(x-1, y).AddDirection(E)
+			}
+			if x == width {
+				break
+			}
+			p := image.Pt(x, y)
+			if i, error = parser.match(line, 4*x+1, "   ", " · ", " * ", " x ", " X "); error != nil {
+				return nil, error
+			}
+			if i == 2 || i == 4 {
+				if error = found.setEntrance(p, line, 4*x+2); error != nil {
+					return nil, error
+				}
+			}
+			if i == 3 || i == 4 {
+				if error = found.setExit(p, line, 4*x+2); error != nil {
+					return nil, error
+				}
+			}
+		}
+	}
+	if error = found.apply(b); error != nil {
+		return nil, error
+	}
+	return b, nil
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
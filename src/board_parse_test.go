@@ -0,0 +1,150 @@
+package board
+
+import (
+	"bytes"
+	"image"
+	"os"
+	"rand"
+	"testing"
+)
+
+// randomBoard opens random passages between the fields, keeping both sides
+// of every passage consistent, and through random sides of the border.
+func randomBoard(rng *rand.Rand, width, height int) Board {
+	b := New(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if rng.Intn(2) == 0 && (x < width-1 || rng.Intn(4) == 0) {
+				b.At(x, y).AddDirection(E)
+				if x < width-1 {
+					b.At(x+1, y).AddDirection(W)
+				}
+			}
+			if rng.Intn(2) == 0 && (y < height-1 || rng.Intn(4) == 0) {
+				b.At(x, y).AddDirection(S)
+				if y < height-1 {
+					b.At(x, y+1).AddDirection(N)
+				}
+			}
+			if x == 0 && rng.Intn(4) == 0 {
+				b.At(x, y).AddDirection(W)
+			}
+			if y == 0 && rng.Intn(4) == 0 {
+				b.At(x, y).AddDirection(N)
+			}
+		}
+	}
+	*b.Entrance() = image.Pt(rng.Intn(width), rng.Intn(height))
+	for {
+		*b.Exit() = image.Pt(rng.Intn(width), rng.Intn(height))
+		if width*height == 1 || !b.Exit().Eq(*b.Entrance()) {
+			break
+		}
+	}
+	return b
+}
+
+func boardsEqual(b1, b2 Board) bool {
+	if b1.Width() != b2.Width() || b1.Height() != b2.Height() ||
+		!b1.Entrance().Eq(*b2.Entrance()) || !b1.Exit().Eq(*b2.Exit()) {
+		return false
+	}
+	for y := 0; y < b1.Height(); y++ {
+		for x := 0; x < b1.Width(); x++ {
+			if b1.At(x, y).Direction() != b2.At(x, y).Direction() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestParsingRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	formats := []struct {
+		Name  string
+		Write func(b Board) string
+		Parse func(text string) (Board, os.Error)
+	}{
+		{"String", func(b Board) string { return b.String() }, ParseString},
+		{"PrettyString", func(b Board) string { return b.PrettyString() }, ParsePretty},
+		{"WriteBox", func(b Board) string {
+			var buf bytes.Buffer
+			path, _ := b.ShortestPath(*b.Entrance(), *b.Exit())
+			WriteBox(&buf, b, path, true)
+			return buf.String()
+		}, ParseBox},
+	}
+	for i := 0; i < 50; i++ {
+		b := randomBoard(rng, 1+rng.Intn(8), 1+rng.Intn(8))
+		for _, format := range formats {
+			text := format.Write(b)
+			for _, parse := range []func(string) (Board, os.Error){format.Parse, Parse} {
+				parsed, error := parse(text)
+				if error != nil {
+					t.Fatalf("Unable to parse the output of %s:\n%s\n%v",
+						format.Name, text, error)
+				}
+				if !boardsEqual(parsed, b) {
+					t.Fatalf("Board parsed from the output of %s differs:\n%s\n%s",
+						format.Name, text, parsed.String())
+				}
+			}
+		}
+	}
+}
+
+func TestParsingErrors(t *testing.T) {
+	testCases := []struct {
+		Text         string
+		Line, Column int
+	}{
+		// A wall of the second field is broken.
+		{"" +
+			"+-++-+\n" +
+			"|*  x|\n" +
+			"+-++=+\n", 3, 5},
+		// The second line is too short.
+		{"" +
+			"+-++-+\n" +
+			"|*  x\n" +
+			"+-++-+\n", 2, 6},
+		{"" +
+			"+--+--+\n" +
+			"|*   x|\n" +
+			"+--+-|+\n", 3, 6},
+		{"" +
+			"+--+--+\n" +
+			"|*  *x|\n" +
+			"+--+--+\n", 2, 5},
+		{"" +
+			"+--+--+\n" +
+			"|*    |\n" +
+			"+--+--+\n", 3, 8},
+		{"" +
+			"┌───────┐\n" +
+			"│ *   x │\n" +
+			"└───┴─ ─┘\n", 3, 7},
+		{"" +
+			"┌───────┐\n" +
+			"│ *  x  │\n" +
+			"└───────┘\n", 2, 6},
+		// Columns count the characters of the colour codes.
+		{"" +
+			"┌───────┐\n" +
+			"│\x1b[39;42m * \x1b[0m x  │\n" +
+			"└───────┘\n", 2, 18},
+	}
+	for _, test := range testCases {
+		_, error := Parse(test.Text)
+		parseError, ok := error.(*ParseError)
+		if !ok {
+			t.Errorf("Parsing\n%s\nfailed with %v, expected a parse error", test.Text, error)
+			continue
+		}
+		if parseError.Line != test.Line || parseError.Column != test.Column {
+			t.Errorf("Parsing\n%s\nfailed at %d:%d (%s), expected %d:%d", test.Text,
+				parseError.Line, parseError.Column, parseError.Message, test.Line, test.Column)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package board
+
+import (
+	"fmt"
+	"image"
+	"os"
+)
+
+// Path is a sequence of adjacent fields.
+type Path []image.Point
+
+// Length returns the number of steps needed to walk the path.
+func (self Path) Length() int {
+	if len(self) == 0 {
+		return 0
+	}
+	return len(self) - 1
+}
+
+func (self *boardImpl) Solve() (Path, os.Error) {
+	return self.ShortestPath(*self.Entrance(), *self.Exit())
+}
+
+// ShortestPath finds the shortest path between two fields using a breadth
+// first search, so that it's also correct for boards with loops.
+func (self *boardImpl) ShortestPath(from, to image.Point) (Path, os.Error) {
+	width, height := self.Width(), self.Height()
+	boardRectangle := image.Rect(0, 0, width, height)
+	if !from.In(boardRectangle) || !to.In(boardRectangle) {
+		return nil, fmt.Errorf("Path from %v to %v leads out of the board",
+			from, to)
+	}
+	visitMatrix := newMatrix(self)
+	cameFrom := make([][]Direction, height)
+	for y := range cameFrom {
+		cameFrom[y] = make([]Direction, width)
+	}
+	// Fields are queued by their indices, which takes less memory than
+	// queueing points on huge boards.
+	queue := []int{from.Y*width + from.X}
+	visitMatrix[from.Y][from.X] = true
+	for head := 0; head < len(queue); head++ {
+		p := image.Pt(queue[head]%width, queue[head]/width)
+		if p.Eq(to) {
+			return tracePath(cameFrom, from, to), nil
+		}
+		fieldDir := self.At(p.X, p.Y).Direction()
+		for dir := minDirection; dir <= maxDirection; dir <<= 1 {
+			if fieldDir&dir == 0 {
+				continue
+			}
+			delta, _ := dir.Delta()
+			p2 := p.Add(delta)
+			if !p2.In(boardRectangle) || visitMatrix[p2.Y][p2.X] {
+				continue
+			}
+			visitMatrix[p2.Y][p2.X] = true
+			cameFrom[p2.Y][p2.X] = dir.Opposite()
+			queue = append(queue, p2.Y*width+p2.X)
+		}
+	}
+	return nil, fmt.Errorf("There is no path from %v to %v", from, to)
+}
+
+func tracePath(cameFrom [][]Direction, from, to image.Point) Path {
+	path := Path{to}
+	for p := to; !p.Eq(from); {
+		delta, _ := cameFrom[p.Y][p.X].Delta()
+		p = p.Add(delta)
+		path = append(path, p)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
@@ -0,0 +1,108 @@
+package board
+
+import (
+	"image"
+	"testing"
+)
+
+// loopBoard has a loop around its middle wall:
+//
+//	+-+-+-+
+//	 *    |
+//	+ +-+ +
+//	|     |
+//	+-+-+ +
+//	|    x
+//	+-+-+-+
+var loopBoard boardImpl = boardImpl{
+	fields: [][]Field{
+		{Field(E | S | W), Field(E | W), Field(S | W)},
+		{Field(N | E), Field(E | W), Field(N | S | W)},
+		{Field(E), Field(E | W), Field(N | E | W)},
+	},
+	entrance: image.Pt(0, 0),
+	exit:     image.Pt(2, 2),
+}
+
+func pathsEqual(p1, p2 Path) bool {
+	if len(p1) != len(p2) {
+		return false
+	}
+	for i := range p1 {
+		if !p1[i].Eq(p2[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSolving(t *testing.T) {
+	path, error := loopBoard.Solve()
+	if error != nil {
+		t.Fatalf("Unexpected error: %v", error)
+	}
+	expected := Path{
+		image.Pt(0, 0), image.Pt(1, 0), image.Pt(2, 0),
+		image.Pt(2, 1), image.Pt(2, 2),
+	}
+	if !pathsEqual(path, expected) {
+		t.Errorf("Solution is %v, expected %v", path, expected)
+	}
+	if path.Length() != 4 {
+		t.Errorf("Solution length is %d, expected 4", path.Length())
+	}
+}
+
+type shortestPathTest struct {
+	From, To image.Point
+	Length   int
+}
+
+var shortestPathTests []shortestPathTest = []shortestPathTest{
+	{image.Pt(1, 1), image.Pt(1, 1), 0},
+	{image.Pt(0, 1), image.Pt(2, 0), 3},
+	{image.Pt(1, 1), image.Pt(1, 0), 3},
+	{image.Pt(2, 2), image.Pt(0, 2), 2},
+}
+
+func TestShortestPaths(t *testing.T) {
+	for i, test := range shortestPathTests {
+		path, error := loopBoard.ShortestPath(test.From, test.To)
+		if error != nil {
+			t.Errorf("Unexpected error in test %d: %v", i, error)
+			continue
+		}
+		if path.Length() != test.Length {
+			t.Errorf("Path %v in test %d has length %d, expected %d",
+				path, i, path.Length(), test.Length)
+		}
+		if !path[0].Eq(test.From) || !path[len(path)-1].Eq(test.To) {
+			t.Errorf("Path %v in test %d doesn't lead from %v to %v",
+				path, i, test.From, test.To)
+		}
+		for j := 1; j < len(path); j++ {
+			d := path[j].Sub(path[j-1])
+			if d.X*d.X+d.Y*d.Y != 1 {
+				t.Errorf("Path %v in test %d is not continuous", path, i)
+				break
+			}
+		}
+	}
+}
+
+func TestShortestPathErrors(t *testing.T) {
+	// +-+-+
+	//  *|x
+	// +-+-+
+	board := boardImpl{
+		fields:   [][]Field{{Field(W), Field(E)}},
+		entrance: image.Pt(0, 0),
+		exit:     image.Pt(1, 0),
+	}
+	if path, error := board.Solve(); error == nil {
+		t.Errorf("Solving an unsolvable board resulted in %v", path)
+	}
+	if path, error := board.ShortestPath(image.Pt(0, 0), image.Pt(2, 0)); error == nil {
+		t.Errorf("Found a path %v out of the board", path)
+	}
+}
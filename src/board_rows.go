@@ -0,0 +1,104 @@
+package board
+
+import (
+	"bufio"
+	"image"
+	"io"
+	"os"
+)
+
+// Rows is a source of board fields, delivered one row at a time, from top to
+// bottom. It allows processing boards that don't fit in memory.
+type Rows interface {
+	Width() int
+	Height() int
+	Entrance() image.Point
+	Exit() image.Point
+	// NextRow returns os.EOF after the last row.
+	NextRow() ([]Field, os.Error)
+}
+
+type boardRows struct {
+	board Board
+	y     int
+}
+
+func RowsOf(b Board) Rows {
+	return &boardRows{board: b}
+}
+
+func (self *boardRows) Width() int            { return self.board.Width() }
+func (self *boardRows) Height() int           { return self.board.Height() }
+func (self *boardRows) Entrance() image.Point { return *self.board.Entrance() }
+func (self *boardRows) Exit() image.Point     { return *self.board.Exit() }
+
+func (self *boardRows) NextRow() ([]Field, os.Error) {
+	if self.y >= self.board.Height() {
+		return nil, os.EOF
+	}
+	row := make([]Field, self.board.Width())
+	for x := range row {
+		row[x] = *self.board.At(x, self.y)
+	}
+	self.y++
+	return row, nil
+}
+
+// WritePrettyRows writes the rows in the format of Board.PrettyString.
+func WritePrettyRows(w io.Writer, rows Rows) os.Error {
+	out := bufio.NewWriter(w)
+	entrance, exit := rows.Entrance(), rows.Exit()
+	var row []Field
+	for y := 0; ; y++ {
+		nextRow, error := rows.NextRow()
+		if error == os.EOF {
+			break
+		}
+		if error != nil {
+			return error
+		}
+		row = nextRow
+		writePrettyWalls(out, row, N)
+
+		for x, field := range row {
+			dir := field.Direction()
+			if dir&W != 0 {
+				out.WriteString(" ")
+			} else {
+				out.WriteString("|")
+			}
+			point := image.Pt(x, y)
+			if point.Eq(entrance) {
+				out.WriteString("*")
+			} else {
+				out.WriteString(" ")
+			}
+			if point.Eq(exit) {
+				out.WriteString("x")
+			} else {
+				out.WriteString(" ")
+			}
+		}
+
+		if row[len(row)-1].Direction()&E != 0 {
+			out.WriteString(" \n")
+		} else {
+			out.WriteString("|\n")
+		}
+	}
+	if row != nil {
+		writePrettyWalls(out, row, S)
+	}
+	return out.Flush()
+}
+
+func writePrettyWalls(out *bufio.Writer, row []Field, side Direction) {
+	for _, field := range row {
+		if field.Direction()&side != 0 {
+			out.WriteString("+  ")
+		} else {
+			out.WriteString("+--")
+		}
+	}
+	out.WriteString("+\n")
+}
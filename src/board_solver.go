@@ -0,0 +1,156 @@
+package board
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"sort"
+)
+
+// SolveObserver is notified of the progress of a solver, for example to
+// animate it.
+type SolveObserver interface {
+	// Entered is called when the solver reaches a field.
+	Entered(p image.Point)
+	// Backtracked is called when the solver gives up on a field, because
+	// all the ways from it lead to fields it has already been to.
+	Backtracked(p image.Point)
+	// GoalFound is called with the path found from the entrance to the exit.
+	GoalFound(path Path)
+}
+
+// Solver finds a path from the entrance to the exit of a board, reporting
+// its progress to the observer, which may be nil.
+type Solver interface {
+	Solve(b Board, observer SolveObserver) (Path, os.Error)
+}
+
+type SolverFunc func(b Board, observer SolveObserver) (Path, os.Error)
+
+func (self SolverFunc) Solve(b Board, observer SolveObserver) (Path, os.Error) {
+	if observer == nil {
+		observer = nopSolveObserver{}
+	}
+	return self(b, observer)
+}
+
+var solvers map[string]Solver = map[string]Solver{
+	"dfs": SolverFunc(depthFirst),
+	"bfs": SolverFunc(breadthFirst),
+}
+
+func LookupSolver(name string) (solver Solver, error os.Error) {
+	solver, ok := solvers[name]
+	if !ok {
+		error = os.NewError("Unknown solver " + name)
+	}
+	return
+}
+
+func SolverNames() []string {
+	names := make([]string, 0, len(solvers))
+	for name := range solvers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type nopSolveObserver struct{}
+
+func (self nopSolveObserver) Entered(p image.Point)     {}
+func (self nopSolveObserver) Backtracked(p image.Point) {}
+func (self nopSolveObserver) GoalFound(path Path)       {}
+
+// passages returns the directions leading from the field to other fields of
+// the board, leaving out the openings of the entrance and exit.
+func passages(b Board, p image.Point) []Direction {
+	result := make([]Direction, 0, 4)
+	fieldDir := b.At(p.X, p.Y).Direction()
+	for dir := minDirection; dir <= maxDirection; dir <<= 1 {
+		if fieldDir&dir == 0 {
+			continue
+		}
+		delta, _ := dir.Delta()
+		if p.Add(delta).In(image.Rect(0, 0, b.Width(), b.Height())) {
+			result = append(result, dir)
+		}
+	}
+	return result
+}
+
+// depthFirst follows a single path until it gets stuck, then backtracks to
+// the last field with a way it hasn't tried yet. The path found is the stack
+// of fields it is in when reaching the exit.
+func depthFirst(b Board, observer SolveObserver) (Path, os.Error) {
+	entrance, exit := *b.Entrance(), *b.Exit()
+	visited := newMatrix(b)
+	visited[entrance.Y][entrance.X] = true
+	stack := Path{entrance}
+	observer.Entered(entrance)
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		if p.Eq(exit) {
+			path := make(Path, len(stack))
+			copy(path, stack)
+			observer.GoalFound(path)
+			return path, nil
+		}
+		moved := false
+		for _, dir := range passages(b, p) {
+			delta, _ := dir.Delta()
+			q := p.Add(delta)
+			if !visited[q.Y][q.X] {
+				visited[q.Y][q.X] = true
+				stack = append(stack, q)
+				observer.Entered(q)
+				moved = true
+				break
+			}
+		}
+		if !moved {
+			stack = stack[:len(stack)-1]
+			observer.Backtracked(p)
+		}
+	}
+	return nil, fmt.Errorf("There is no path from %v to %v", entrance, exit)
+}
+
+// breadthFirst spreads out from the entrance in all directions at once, so
+// that the path found is the shortest one. Fields from which the search can't
+// spread any further are reported as backtracked.
+func breadthFirst(b Board, observer SolveObserver) (Path, os.Error) {
+	width := b.Width()
+	entrance, exit := *b.Entrance(), *b.Exit()
+	visited := newMatrix(b)
+	cameFrom := make([][]Direction, b.Height())
+	for y := range cameFrom {
+		cameFrom[y] = make([]Direction, width)
+	}
+	visited[entrance.Y][entrance.X] = true
+	queue := []int{entrance.Y*width + entrance.X}
+	for head := 0; head < len(queue); head++ {
+		p := image.Pt(queue[head]%width, queue[head]/width)
+		observer.Entered(p)
+		if p.Eq(exit) {
+			path := tracePath(cameFrom, entrance, exit)
+			observer.GoalFound(path)
+			return path, nil
+		}
+		spread := false
+		for _, dir := range passages(b, p) {
+			delta, _ := dir.Delta()
+			q := p.Add(delta)
+			if !visited[q.Y][q.X] {
+				visited[q.Y][q.X] = true
+				cameFrom[q.Y][q.X] = dir.Opposite()
+				queue = append(queue, q.Y*width+q.X)
+				spread = true
+			}
+		}
+		if !spread {
+			observer.Backtracked(p)
+		}
+	}
+	return nil, fmt.Errorf("There is no path from %v to %v", entrance, exit)
+}
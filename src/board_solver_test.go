@@ -0,0 +1,89 @@
+package board
+
+import (
+	"image"
+	"testing"
+)
+
+type recordingSolveObserver struct {
+	entered, backtracked [][]bool
+	goals                []Path
+	backtrackedUnentered bool
+}
+
+func (self *recordingSolveObserver) Entered(p image.Point) {
+	self.entered[p.Y][p.X] = true
+}
+
+func (self *recordingSolveObserver) Backtracked(p image.Point) {
+	if !self.entered[p.Y][p.X] {
+		self.backtrackedUnentered = true
+	}
+	self.backtracked[p.Y][p.X] = true
+}
+
+func (self *recordingSolveObserver) GoalFound(path Path) {
+	self.goals = append(self.goals, path)
+}
+
+func isPathThrough(b Board, path Path) bool {
+	if len(path) == 0 || !path[0].Eq(*b.Entrance()) || !path[len(path)-1].Eq(*b.Exit()) {
+		return false
+	}
+	for i := 1; i < len(path); i++ {
+		p, q := path[i-1], path[i]
+		found := false
+		for _, dir := range passages(b, p) {
+			delta, _ := dir.Delta()
+			found = found || p.Add(delta).Eq(q)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSolvers(t *testing.T) {
+	for _, name := range SolverNames() {
+		solver, error := LookupSolver(name)
+		if error != nil {
+			t.Fatalf("Unable to look up %s: %v", name, error)
+		}
+		observer := &recordingSolveObserver{
+			entered:     newMatrix(&loopBoard),
+			backtracked: newMatrix(&loopBoard),
+		}
+		path, error := solver.Solve(&loopBoard, observer)
+		if error != nil {
+			t.Errorf("Solver %s failed: %v", name, error)
+			continue
+		}
+		if !isPathThrough(&loopBoard, path) {
+			t.Errorf("Solver %s found an invalid path %v", name, path)
+		}
+		if len(observer.goals) != 1 || !pathsEqual(observer.goals[0], path) {
+			t.Errorf("Solver %s reported goals %v, expected %v",
+				name, observer.goals, path)
+		}
+		if observer.backtrackedUnentered {
+			t.Errorf("Solver %s backtracked from a field it didn't enter", name)
+		}
+		for _, p := range path {
+			if !observer.entered[p.Y][p.X] || observer.backtracked[p.Y][p.X] {
+				t.Errorf("Solver %s didn't report entering %v on the path", name, p)
+			}
+		}
+		if _, error := solver.Solve(New(2, 2), nil); error == nil {
+			t.Errorf("Solver %s solved a board without passages", name)
+		}
+	}
+}
+
+func TestBreadthFirstSolverFindsShortestPath(t *testing.T) {
+	solver, _ := LookupSolver("bfs")
+	path, _ := solver.Solve(&loopBoard, nil)
+	if path.Length() != 4 {
+		t.Errorf("Solution length is %d, expected 4", path.Length())
+	}
+}
@@ -441,3 +441,143 @@ func TestComplexity(t *testing.T) {
 		}
 	}
 }
+
+func TestPrettyString(t *testing.T) {
+	board := boardImpl{
+		fields: [][]Field{
+			{Field(E | S), Field(E | S | W), Field(S | W)},
+			{Field(N | S), Field(N | S), Field(N)},
+		},
+		entrance: image.Pt(1, 1),
+		exit:     image.Pt(0, 1),
+	}
+	expected := "" +
+		"+--+--+--+\n" +
+		"|        |\n" +
+		"+  +  +  +\n" +
+		"| x|* |  |\n" +
+		"+  +  +--+\n"
+	if board.PrettyString() != expected {
+		t.Errorf("Pretty string is\n%s\nexpected\n%s",
+			board.PrettyString(), expected)
+	}
+}
+
+func TestWalkingLoops(t *testing.T) {
+	board := loopBoard
+	if !board.Validate() {
+		t.Fatal("Test is broken")
+	}
+	solution, error := board.Walk(true)
+	if error != nil {
+		t.Fatalf("Unexpected error: %v", error)
+	}
+	if !solution[0][0] || !solution[1][2] || !solution[2][2] ||
+		solution[2][0] || solution[2][1] {
+		t.Errorf("Solution %v doesn't lead from the entrance to the exit",
+			solution)
+	}
+	if board.Loops() != 1 {
+		t.Errorf("Number of loops is %d, expected 1", board.Loops())
+	}
+	if board.DeadEnds() != 1 {
+		t.Errorf("Number of dead ends is %d, expected 1", board.DeadEnds())
+	}
+	if board.Complexity() != 4 {
+		t.Errorf("Complexity is %d, expected 4", board.Complexity())
+	}
+}
+
+// serpentineBoard returns a board consisting of a single corridor winding
+// from the top left corner through all the rows, which is the worst case for
+// walking it recursively.
+func serpentineBoard(width, height int) *boardImpl {
+	board := New(width, height).(*boardImpl)
+	for y, row := range board.fields {
+		for x := 0; x+1 < width; x++ {
+			row[x].AddDirection(E)
+			row[x+1].AddDirection(W)
+		}
+		if y+1 < height {
+			x := 0
+			if y%2 == 0 {
+				x = width - 1
+			}
+			row[x].AddDirection(S)
+			board.fields[y+1][x].AddDirection(N)
+		}
+	}
+	board.fields[0][0].AddDirection(W)
+	if height%2 == 1 {
+		board.exit = image.Pt(width-1, height-1)
+		board.fields[height-1][width-1].AddDirection(E)
+	} else {
+		board.exit = image.Pt(0, height-1)
+		board.fields[height-1][0].AddDirection(W)
+	}
+	return board
+}
+
+func TestWalkingLongCorridor(t *testing.T) {
+	const width, height = 1000, 1000
+	board := serpentineBoard(width, height)
+	if !board.Validate() {
+		t.Fatal("Test is broken")
+	}
+	solution, error := board.Walk(true)
+	if error != nil {
+		t.Fatalf("Unexpected error: %v", error)
+	}
+	for y, row := range solution {
+		for x, onPath := range row {
+			if !onPath {
+				t.Fatalf("Field (%d, %d) is not a part of the solution", x, y)
+			}
+		}
+	}
+	path, error := board.Solve()
+	if error != nil {
+		t.Fatalf("Unexpected error: %v", error)
+	}
+	if path.Length() != width*height-1 {
+		t.Errorf("Solution length is %d, expected %d",
+			path.Length(), width*height-1)
+	}
+}
+
+func benchmarkWalking(b *testing.B, size int, solve bool) {
+	b.StopTimer()
+	board := serpentineBoard(size, size)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		board.Walk(solve)
+	}
+}
+
+func BenchmarkWalking100(b *testing.B)   { benchmarkWalking(b, 100, false) }
+func BenchmarkWalking1000(b *testing.B)  { benchmarkWalking(b, 1000, false) }
+func BenchmarkWalking10000(b *testing.B) { benchmarkWalking(b, 10000, false) }
+
+func BenchmarkWalkingSolve1000(b *testing.B)  { benchmarkWalking(b, 1000, true) }
+func BenchmarkWalkingSolve10000(b *testing.B) { benchmarkWalking(b, 10000, true) }
+
+func benchmarkSolving(b *testing.B, size int) {
+	b.StopTimer()
+	board := serpentineBoard(size, size)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		board.Solve()
+	}
+}
+
+func BenchmarkSolving1000(b *testing.B)  { benchmarkSolving(b, 1000) }
+func BenchmarkSolving10000(b *testing.B) { benchmarkSolving(b, 10000) }
+
+func BenchmarkValidating10000(b *testing.B) {
+	b.StopTimer()
+	board := serpentineBoard(10000, 10000)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		board.Validate()
+	}
+}
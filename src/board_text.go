@@ -0,0 +1,258 @@
+package board
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// TextFormat writes the board as text, highlighting the path, which may be
+// nil, if the format is able to.
+type TextFormat func(w io.Writer, b Board, path Path) os.Error
+
+var textFormats map[string]TextFormat = map[string]TextFormat{
+	"ascii": func(w io.Writer, b Board, path Path) os.Error {
+		_, error := io.WriteString(w, b.String())
+		return error
+	},
+	"pretty": func(w io.Writer, b Board, path Path) os.Error {
+		return WritePrettyRows(w, RowsOf(b))
+	},
+	"box": func(w io.Writer, b Board, path Path) os.Error {
+		return WriteBox(w, b, path, false)
+	},
+	"box-ansi": func(w io.Writer, b Board, path Path) os.Error {
+		return WriteBox(w, b, path, true)
+	},
+	"half-block": func(w io.Writer, b Board, path Path) os.Error {
+		return WriteHalfBlocks(w, b, path, false)
+	},
+	"half-block-ansi": func(w io.Writer, b Board, path Path) os.Error {
+		return WriteHalfBlocks(w, b, path, true)
+	},
+}
+
+func LookupTextFormat(name string) (format TextFormat, error os.Error) {
+	format, ok := textFormats[name]
+	if !ok {
+		error = os.NewError("Unknown text format " + name)
+	}
+	return
+}
+
+func TextFormatNames() []string {
+	names := make([]string, 0, len(textFormats))
+	for name := range textFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pixel is what a character cell of a text picture shows.
+type pixel uint8
+
+const (
+	emptyPixel pixel = iota
+	wallPixel
+	pathPixel
+	entrancePixel
+	exitPixel
+)
+
+// ANSI codes of the foreground colours of the pixels; adding 10 gives the
+// background colour. Walls use the default colour of the terminal.
+var pixelColors = []int{39, 39, 33, 32, 31}
+
+// textPixels lays the board out on a grid twice as large as the board plus
+// one: fields are at odd coordinates, the walls and passages between them
+// at one odd and one even coordinate and the corners at even coordinates.
+func textPixels(b Board, path Path) [][]pixel {
+	width, height := 2*b.Width()+1, 2*b.Height()+1
+	pixels := make([][]pixel, height)
+	for y := range pixels {
+		pixels[y] = make([]pixel, width)
+	}
+	for y := 0; y < b.Height(); y++ {
+		for x := 0; x < b.Width(); x++ {
+			dir := b.At(x, y).Direction()
+			if dir&N == 0 {
+				pixels[2*y][2*x+1] = wallPixel
+			}
+			if dir&W == 0 {
+				pixels[2*y+1][2*x] = wallPixel
+			}
+			if y == b.Height()-1 && dir&S == 0 {
+				pixels[2*y+2][2*x+1] = wallPixel
+			}
+			if x == b.Width()-1 && dir&E == 0 {
+				pixels[2*y+1][2*x+2] = wallPixel
+			}
+		}
+	}
+	for y := 0; y < height; y += 2 {
+		for x := 0; x < width; x += 2 {
+			pixels[y][x] = wallPixel
+		}
+	}
+	for i, p := range path {
+		pixels[2*p.Y+1][2*p.X+1] = pathPixel
+		if i > 0 {
+			previous := path[i-1]
+			pixels[p.Y+previous.Y+1][p.X+previous.X+1] = pathPixel
+		}
+	}
+	entrance, exit := *b.Entrance(), *b.Exit()
+	pixels[2*entrance.Y+1][2*entrance.X+1] = entrancePixel
+	pixels[2*exit.Y+1][2*exit.X+1] = exitPixel
+	return pixels
+}
+
+// boxJunctions holds the box-drawing characters joining the walls leading
+// from a corner, indexed by a bit mask of the walls going up, right, down and
+// left, in this order from the lowest bit.
+var boxJunctions = []string{
+	" ", "╵", "╶", "└", "╷", "│", "┌", "├",
+	"╴", "┘", "─", "┴", "┐", "┤", "┬", "┼",
+}
+
+// textWriter writes text pictures, switching the ANSI colours only when
+// they change.
+type textWriter struct {
+	*bufio.Writer
+	ansi   bool
+	fg, bg int
+}
+
+func newTextWriter(w io.Writer, ansi bool) *textWriter {
+	return &textWriter{
+		Writer: bufio.NewWriter(w),
+		ansi:   ansi,
+		fg:     pixelColors[emptyPixel],
+		bg:     pixelColors[emptyPixel] + 10,
+	}
+}
+
+func (self *textWriter) write(text string, fg, bg pixel) {
+	fgColor, bgColor := pixelColors[fg], pixelColors[bg]+10
+	if self.ansi && (fgColor != self.fg || bgColor != self.bg) {
+		fmt.Fprintf(self, "\x1b[%d;%dm", fgColor, bgColor)
+		self.fg, self.bg = fgColor, bgColor
+	}
+	self.WriteString(text)
+}
+
+func (self *textWriter) endLine() {
+	if self.ansi && (self.fg != pixelColors[emptyPixel] || self.bg != pixelColors[emptyPixel]+10) {
+		self.WriteString("\x1b[0m")
+		self.fg, self.bg = pixelColors[emptyPixel], pixelColors[emptyPixel]+10
+	}
+	self.WriteString("\n")
+}
+
+// WriteBox draws the board with Unicode box-drawing characters, each field
+// three characters wide. The entrance is marked with *, the exit with x, or
+// both with X if they are the same field. The path is drawn with dots, and
+// if ansi is set, the path, entrance and exit are also coloured with ANSI
+// escape codes.
+func WriteBox(w io.Writer, b Board, path Path, ansi bool) os.Error {
+	pixels := textPixels(b, path)
+	out := newTextWriter(w, ansi)
+	for y, row := range pixels {
+		for x, p := range row {
+			var text string
+			switch {
+			case x%2 == 0 && y%2 == 0:
+				text = boxJunctions[boxArms(pixels, x, y)]
+			case p == wallPixel && x%2 == 0:
+				text = "│"
+			case p == wallPixel:
+				text = "───"
+			case p == entrancePixel:
+				text = " * "
+			case p == exitPixel && b.Entrance().Eq(*b.Exit()):
+				text = " X "
+			case p == exitPixel:
+				text = " x "
+			case p == pathPixel && x%2 == 0:
+				text = "·"
+			case p == pathPixel:
+				text = " · "
+			case x%2 == 0:
+				text = " "
+			default:
+				text = "   "
+			}
+			if p == wallPixel {
+				out.write(text, emptyPixel, emptyPixel)
+			} else {
+				out.write(text, emptyPixel, p)
+			}
+		}
+		out.endLine()
+	}
+	return out.Flush()
+}
+
+func boxArms(pixels [][]pixel, x, y int) int {
+	arms := 0
+	if y > 0 && pixels[y-1][x] == wallPixel {
+		arms |= 1
+	}
+	if x < len(pixels[y])-1 && pixels[y][x+1] == wallPixel {
+		arms |= 2
+	}
+	if y < len(pixels)-1 && pixels[y+1][x] == wallPixel {
+		arms |= 4
+	}
+	if x > 0 && pixels[y][x-1] == wallPixel {
+		arms |= 8
+	}
+	return arms
+}
+
+// WriteHalfBlocks draws the board compactly with half-block characters, so
+// that every line of text holds two rows of walls and fields, each a single
+// character wide. Only if ansi is set, the path, entrance and exit are shown,
+// coloured with ANSI escape codes.
+func WriteHalfBlocks(w io.Writer, b Board, path Path, ansi bool) os.Error {
+	if !ansi {
+		path = nil
+	}
+	pixels := textPixels(b, path)
+	out := newTextWriter(w, ansi)
+	for y := 0; y < len(pixels); y += 2 {
+		for x, upper := range pixels[y] {
+			lower := emptyPixel
+			if y+1 < len(pixels) {
+				lower = pixels[y+1][x]
+			}
+			if !ansi {
+				upper, lower = onlyWalls(upper), onlyWalls(lower)
+			}
+			// The foreground colour is that of the wall, if there is one,
+			// as the terminal has no background colour for it.
+			switch {
+			case upper == lower && upper == emptyPixel:
+				out.write(" ", emptyPixel, emptyPixel)
+			case upper == lower:
+				out.write("█", upper, emptyPixel)
+			case lower == wallPixel || upper == emptyPixel:
+				out.write("▄", lower, upper)
+			default:
+				out.write("▀", upper, lower)
+			}
+		}
+		out.endLine()
+	}
+	return out.Flush()
+}
+
+func onlyWalls(p pixel) pixel {
+	if p != wallPixel {
+		return emptyPixel
+	}
+	return p
+}
@@ -0,0 +1,105 @@
+package board
+
+import (
+	"bytes"
+	"image"
+	"strings"
+	"testing"
+)
+
+// +--+--+--+
+// |        |
+// +  +  +  +
+// | x|* |  |
+// +  +  +--+
+var textBoard boardImpl = boardImpl{
+	fields: [][]Field{
+		{Field(E | S), Field(E | S | W), Field(S | W)},
+		{Field(N | S), Field(N | S), Field(N)},
+	},
+	entrance: image.Pt(1, 1),
+	exit:     image.Pt(0, 1),
+}
+
+func TestWritingText(t *testing.T) {
+	path, _ := textBoard.Solve()
+	testCases := []struct {
+		Name     string
+		Path     Path
+		Expected string
+	}{
+		{"box", nil, "" +
+			"┌───────────┐\n" +
+			"│           │\n" +
+			"│   ╷   ╷   │\n" +
+			"│ x │ * │   │\n" +
+			"╵   ╵   └───┘\n"},
+		{"box", path, "" +
+			"┌───────────┐\n" +
+			"│ · · ·     │\n" +
+			"│ · ╷ · ╷   │\n" +
+			"│ x │ * │   │\n" +
+			"╵   ╵   └───┘\n"},
+		{"half-block", path, "" +
+			"█▀▀▀▀▀█\n" +
+			"█ █ █ █\n" +
+			"▀ ▀ ▀▀▀\n"},
+	}
+	for _, test := range testCases {
+		format, error := LookupTextFormat(test.Name)
+		if error != nil {
+			t.Fatalf("Unable to look up format %s: %v", test.Name, error)
+		}
+		var buf bytes.Buffer
+		if error = format(&buf, &textBoard, test.Path); error != nil {
+			t.Fatalf("Unable to write format %s: %v", test.Name, error)
+		}
+		if buf.String() != test.Expected {
+			t.Errorf("Format %s is\n%s\nexpected\n%s",
+				test.Name, buf.String(), test.Expected)
+		}
+	}
+}
+
+// stripANSI removes the ANSI escape codes setting colours from the text.
+func stripANSI(text string) string {
+	var buf bytes.Buffer
+	for {
+		start := strings.Index(text, "\x1b[")
+		end := strings.Index(text[max(start, 0):], "m")
+		if start < 0 || end < 0 {
+			break
+		}
+		buf.WriteString(text[:start])
+		text = text[start+end+1:]
+	}
+	buf.WriteString(text)
+	return buf.String()
+}
+
+func TestWritingANSIColors(t *testing.T) {
+	path, _ := textBoard.Solve()
+	var plain, colored bytes.Buffer
+	WriteBox(&plain, &textBoard, path, false)
+	WriteBox(&colored, &textBoard, path, true)
+	if stripANSI(colored.String()) != plain.String() {
+		t.Errorf("Coloured box is\n%s\nexpected\n%s",
+			stripANSI(colored.String()), plain.String())
+	}
+	for _, code := range []string{"\x1b[39;43m", "\x1b[39;42m", "\x1b[39;41m"} {
+		if !strings.Contains(colored.String(), code) {
+			t.Errorf("Coloured box doesn't contain %q", code)
+		}
+	}
+	colored.Reset()
+	WriteHalfBlocks(&colored, &textBoard, path, true)
+	// The entrance and exit are in lower halves, below the path.
+	for _, code := range []string{"\x1b[33;42m", "\x1b[33;41m"} {
+		if !strings.Contains(colored.String(), code) {
+			t.Errorf("Coloured half blocks don't contain %q", code)
+		}
+	}
+	if strings.Contains(plain.String(), "\x1b") {
+		t.Errorf("Box without colours contains escape codes")
+	}
+}
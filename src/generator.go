@@ -2,73 +2,145 @@ package generator
 
 import (
 	"board"
-	"container/heap"
+	"fmt"
 	"image"
+	"os"
 	"rand"
+	"strconv"
+	"strings"
 )
 
-type fieldHeapElement struct {
-	Coords image.Point
-	Weight int
+// Strategy picks the next field to grow the maze from. The active fields are
+// passed in the order they were added, from the oldest to the newest.
+type Strategy interface {
+	Select(n int, rng *rand.Rand) int
 }
 
-type fieldHeap []fieldHeapElement
+type StrategyFunc func(n int, rng *rand.Rand) int
 
-func (self *fieldHeap) Push(x interface{}) {
-	*self = append(*self, x.(fieldHeapElement))
+func (self StrategyFunc) Select(n int, rng *rand.Rand) int {
+	return self(n, rng)
 }
 
-func (self *fieldHeap) Pop() interface{} {
-	last := len(*self) - 1
-	result := (*self)[last]
-	*self = (*self)[:last]
-	return result
+var (
+	Newest Strategy = StrategyFunc(func(n int, rng *rand.Rand) int { return n - 1 })
+	Oldest Strategy = StrategyFunc(func(n int, rng *rand.Rand) int { return 0 })
+	Middle Strategy = StrategyFunc(func(n int, rng *rand.Rand) int { return n / 2 })
+	Random Strategy = StrategyFunc(func(n int, rng *rand.Rand) int { return rng.Intn(n) })
+)
+
+var strategies map[string]Strategy = map[string]Strategy{
+	"newest": Newest,
+	"oldest": Oldest,
+	"middle": Middle,
+	"random": Random,
+}
+
+type mixedStrategy struct {
+	strategies  []Strategy
+	weights     []int
+	totalWeight int
+}
+
+// Mix returns a strategy that delegates each selection to one of the given
+// strategies, picked randomly with probability proportional to its weight.
+// Each strategy needs a positive weight.
+func Mix(strategies []Strategy, weights []int) (Strategy, os.Error) {
+	if len(strategies) == 0 || len(strategies) != len(weights) {
+		return nil, fmt.Errorf("Can't mix %d strategies with %d weights",
+			len(strategies), len(weights))
+	}
+	mix := &mixedStrategy{strategies: strategies, weights: weights}
+	for _, weight := range weights {
+		mix.totalWeight += weight
+		if weight <= 0 || mix.totalWeight <= 0 {
+			return nil, fmt.Errorf("Invalid strategy weights %v", weights)
+		}
+	}
+	return mix, nil
+}
+
+func (self *mixedStrategy) Select(n int, rng *rand.Rand) int {
+	r := rng.Intn(self.totalWeight)
+	for i, weight := range self.weights {
+		if r < weight {
+			return self.strategies[i].Select(n, rng)
+		}
+		r -= weight
+	}
+	panic("unreachable")
+}
+
+// ParseStrategy parses a strategy name, or a comma-separated list of weighted
+// names, such as "newest:75,random:25".
+func ParseStrategy(spec string) (Strategy, os.Error) {
+	parts := strings.Split(spec, ",")
+	mixed := make([]Strategy, len(parts))
+	weights := make([]int, len(parts))
+	for i, part := range parts {
+		name, weight := part, 1
+		if colon := strings.Index(part, ":"); colon >= 0 {
+			var error os.Error
+			name = part[:colon]
+			weight, error = strconv.Atoi(part[colon+1:])
+			if error != nil || weight <= 0 {
+				return nil, os.NewError("Invalid strategy weight in " + part)
+			}
+		}
+		strategy, ok := strategies[strings.TrimSpace(name)]
+		if !ok {
+			return nil, os.NewError("Unknown strategy " + name)
+		}
+		mixed[i], weights[i] = strategy, weight
+	}
+	if len(mixed) == 1 {
+		return mixed[0], nil
+	}
+	return Mix(mixed, weights)
+}
+
+// GrowingTree grows the maze from a list of active fields, using the strategy
+// to decide which one to extend next. Picking the newest field results in a
+// recursive backtracker, picking a random one in a maze similar to Prim's.
+type GrowingTree struct {
+	Strategy Strategy
 }
 
-func (self fieldHeap) Len() int           { return len(self) }
-func (self fieldHeap) Less(i, j int) bool { return self[i].Weight < self[j].Weight }
-func (self fieldHeap) Swap(i, j int)      { self[i], self[j] = self[j], self[i] }
+func (self GrowingTree) Generate(width, height int, rng *rand.Rand) board.Board {
+	return self.GenerateObserved(width, height, rng, nopObserver{})
+}
 
-func Generate(width, height int) board.Board {
+func (self GrowingTree) GenerateObserved(width, height int, rng *rand.Rand,
+	observer Observer) board.Board {
 	if width < 1 || height < 1 {
 		return nil
 	}
 
 	b := board.New(width, height)
-	*b.Entrance() = image.Pt(rand.Intn(width), 0)
-	*b.Exit() = image.Pt(rand.Intn(width), height-1)
+	*b.Entrance() = image.Pt(rng.Intn(width), 0)
+	*b.Exit() = image.Pt(rng.Intn(width), height-1)
 	boardRectangle := image.Rect(0, 0, width, height)
-	fieldQueue := new(fieldHeap)
-	heap.Init(fieldQueue)
-	heap.Push(fieldQueue, fieldHeapElement{*b.Entrance(), rand.Int()})
-
-	for fieldQueue.Len() > 0 {
-		coords := heap.Pop(fieldQueue).(fieldHeapElement).Coords
-		field := b.At(coords.X, coords.Y)
-		possibleDirections := field.Direction().Negate().Decompose()
-		shuffleDirections(possibleDirections)
-		pickedDirection := board.None
-		var nextCoords image.Point
-		for _, dir := range possibleDirections {
-			delta, _ := dir.Delta()
-			possibleCoords := coords.Add(delta)
-			if possibleCoords.In(boardRectangle) &&
-				b.At(possibleCoords.X, possibleCoords.Y).Direction() ==
-					board.None {
-				pickedDirection = dir
-				nextCoords = possibleCoords
-				break
-			}
+	untouched := func(q image.Point) bool {
+		return b.At(q.X, q.Y).Direction() == board.None
+	}
+	active := []image.Point{*b.Entrance()}
+
+	for len(active) > 0 {
+		observer.Frontier(active)
+		i := self.Strategy.Select(len(active), rng)
+		coords := active[i]
+		possibleDirections := filterDirections(coords, boardRectangle, untouched)
+		if len(possibleDirections) == 0 {
+			active = append(active[:i], active[i+1:]...)
+			continue
 		}
-		if pickedDirection != board.None {
-			nextField := b.At(nextCoords.X, nextCoords.Y)
-			field.AddDirection(pickedDirection)
-			nextField.AddDirection(pickedDirection.Opposite())
-			if !b.Exit().Eq(nextCoords) {
-				heap.Push(fieldQueue,
-					fieldHeapElement{nextCoords, rand.Int()})
-			}
-			heap.Push(fieldQueue, fieldHeapElement{coords, rand.Int()})
+		pickedDirection := randomDirection(rng, possibleDirections)
+		carve(b, coords, pickedDirection, observer)
+		nextCoords := neighbour(coords, pickedDirection)
+		// The exit is left as a dead end, unless it would cut the single
+		// row of a flat board in two.
+		if !b.Exit().Eq(nextCoords) || height == 1 {
+			active = append(active, nextCoords)
 		}
 	}
 
@@ -78,9 +150,6 @@ func Generate(width, height int) board.Board {
 	return b
 }
 
-func shuffleDirections(directions []board.Direction) {
-	for i := 0; i < len(directions); i++ {
-		j := rand.Intn(i + 1)
-		directions[i], directions[j] = directions[j], directions[i]
-	}
+func Generate(width, height int, rng *rand.Rand) board.Board {
+	return GrowingTree{Random}.Generate(width, height, rng)
 }
@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"board"
+	"image"
+	"os"
+	"rand"
+	"sort"
+)
+
+// Algorithm generates a board of a given size. All randomness is taken from
+// the given source, so that the same seed always yields the same board.
+type Algorithm interface {
+	Generate(width, height int, rng *rand.Rand) board.Board
+}
+
+type AlgorithmFunc func(width, height int, rng *rand.Rand, observer Observer) board.Board
+
+func (self AlgorithmFunc) Generate(width, height int, rng *rand.Rand) board.Board {
+	return self.GenerateObserved(width, height, rng, nopObserver{})
+}
+
+func (self AlgorithmFunc) GenerateObserved(width, height int, rng *rand.Rand,
+	observer Observer) board.Board {
+	if width < 1 || height < 1 {
+		return nil
+	}
+	return self(width, height, rng, observer)
+}
+
+var algorithms map[string]Algorithm = map[string]Algorithm{
+	"growing-tree":       GrowingTree{Random},
+	"backtracker":        AlgorithmFunc(backtracker),
+	"prim":               AlgorithmFunc(prim),
+	"kruskal":            AlgorithmFunc(kruskal),
+	"wilson":             AlgorithmFunc(wilson),
+	"aldous-broder":      AlgorithmFunc(aldousBroder),
+	"hunt-and-kill":      AlgorithmFunc(huntAndKill),
+	"eller":              AlgorithmFunc(eller),
+	"binary-tree":        AlgorithmFunc(binaryTree),
+	"sidewinder":         AlgorithmFunc(sidewinder),
+	"recursive-division": AlgorithmFunc(recursiveDivision),
+}
+
+func Register(name string, algorithm Algorithm) {
+	algorithms[name] = algorithm
+}
+
+func Lookup(name string) (algorithm Algorithm, error os.Error) {
+	algorithm, ok := algorithms[name]
+	if !ok {
+		error = os.NewError("Unknown algorithm " + name)
+	}
+	return
+}
+
+func Names() []string {
+	names := make([]string, 0, len(algorithms))
+	for name := range algorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var directions []board.Direction = []board.Direction{
+	board.N, board.E, board.S, board.W,
+}
+
+func neighbour(p image.Point, dir board.Direction) image.Point {
+	delta, _ := dir.Delta()
+	return p.Add(delta)
+}
+
+func filterDirections(p image.Point, rect image.Rectangle,
+	accept func(q image.Point) bool) []board.Direction {
+	result := make([]board.Direction, 0, len(directions))
+	for _, dir := range directions {
+		q := neighbour(p, dir)
+		if q.In(rect) && accept(q) {
+			result = append(result, dir)
+		}
+	}
+	return result
+}
+
+func randomDirection(rng *rand.Rand, dirs []board.Direction) board.Direction {
+	return dirs[rng.Intn(len(dirs))]
+}
+
+func randomPoint(rng *rand.Rand, rect image.Rectangle) image.Point {
+	return image.Pt(rect.Min.X+rng.Intn(rect.Dx()),
+		rect.Min.Y+rng.Intn(rect.Dy()))
+}
+
+func boardRect(b board.Board) image.Rectangle {
+	return image.Rect(0, 0, b.Width(), b.Height())
+}
+
+func carve(b board.Board, p image.Point, dir board.Direction, observer Observer) {
+	q := neighbour(p, dir)
+	b.At(p.X, p.Y).AddDirection(dir)
+	b.At(q.X, q.Y).AddDirection(dir.Opposite())
+	observer.Carved(p, dir)
+}
+
+func wall(b board.Board, p image.Point, dir board.Direction, observer Observer) {
+	q := neighbour(p, dir)
+	field := b.At(p.X, p.Y)
+	field.SetDirection(field.Direction() &^ dir)
+	field = b.At(q.X, q.Y)
+	field.SetDirection(field.Direction() &^ dir.Opposite())
+	observer.Walled(p, dir)
+}
+
+func openEntranceAndExit(b board.Board, rng *rand.Rand) {
+	placeTopBottom(b, rng)
+}
+
+func newMatrix(width, height int) [][]bool {
+	matrix := make([][]bool, height)
+	for y := range matrix {
+		matrix[y] = make([]bool, width)
+	}
+	return matrix
+}
+
+func anyPoint(p image.Point) bool {
+	return true
+}
@@ -0,0 +1,177 @@
+package generator
+
+import (
+	"board"
+	"image"
+	"rand"
+	"testing"
+	"testutil"
+)
+
+var algorithmTestSizes []image.Point = []image.Point{
+	{1, 1}, {1, 6}, {6, 1}, {2, 2}, {10, 5}, {7, 9}, {30, 30}, {3, 40}, {40, 3},
+}
+
+// algorithmTestSeeds are the number of seeds each algorithm is tested with.
+const algorithmTestSeeds = 10
+
+func countPassages(b board.Board) int {
+	passages := 0
+	for y := 0; y < b.Height(); y++ {
+		for x := 0; x < b.Width(); x++ {
+			dir := b.At(x, y).Direction()
+			if x+1 < b.Width() && dir&board.E != 0 {
+				passages++
+			}
+			if y+1 < b.Height() && dir&board.S != 0 {
+				passages++
+			}
+		}
+	}
+	return passages
+}
+
+func checkPerfectMaze(t *testing.T, name string, b board.Board, size image.Point) {
+	if b.Width() != size.X || b.Height() != size.Y {
+		t.Errorf("Board generated by %s has size %dx%d, expected %v",
+			name, b.Width(), b.Height(), size)
+		return
+	}
+	if !b.Validate() {
+		t.Errorf("Board generated by %s doesn't validate:\n%v", name, b)
+		return
+	}
+	visitMatrix, error := b.Walk(false)
+	if error != nil {
+		t.Errorf("Unexpected error in board generated by %s: %v\n%v",
+			name, error, b)
+		return
+	}
+	if !testutil.MatricesEqual(trueMatrix(size.X, size.Y), visitMatrix) {
+		t.Errorf("Board generated by %s is not fully connected:\n%v",
+			name, b)
+	}
+	if passages := countPassages(b); passages != size.X*size.Y-1 {
+		t.Errorf("Board generated by %s has %d passages, expected %d:\n%v",
+			name, passages, size.X*size.Y-1, b)
+	}
+	if b.Entrance().Y != 0 || b.At(b.Entrance().X, 0).Direction()&board.N == 0 {
+		t.Errorf("Entrance of board generated by %s is not open: %v",
+			name, b.Entrance())
+	}
+	exit := *b.Exit()
+	if exit.Y != size.Y-1 || b.At(exit.X, exit.Y).Direction()&board.S == 0 {
+		t.Errorf("Exit of board generated by %s is not open: %v",
+			name, exit)
+	}
+}
+
+func TestAlgorithms(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for _, name := range Names() {
+		algorithm, error := Lookup(name)
+		if error != nil {
+			t.Fatalf("Unable to look up %s: %v", name, error)
+		}
+		for seed := 0; seed < algorithmTestSeeds; seed++ {
+			for _, size := range algorithmTestSizes {
+				checkPerfectMaze(t, name, algorithm.Generate(size.X, size.Y, rng), size)
+			}
+		}
+		if b := algorithm.Generate(0, 3, rng); b != nil {
+			t.Errorf("Algorithm %s generated a board of width 0", name)
+		}
+	}
+}
+
+func TestGeneratingReproducibly(t *testing.T) {
+	generate := func(algorithm Algorithm, seed int64) string {
+		rng := rand.New(rand.NewSource(seed))
+		b := algorithm.Generate(12, 8, rng)
+		Braid(b, 0.5, rng)
+		PlacementFunc(placeRandomly).Place(b, rng)
+		return b.String()
+	}
+	for _, name := range Names() {
+		algorithm, _ := Lookup(name)
+		first := generate(algorithm, 42)
+		if second := generate(algorithm, 42); second != first {
+			t.Errorf("Algorithm %s generated different boards from one seed:\n%s\n%s",
+				name, first, second)
+		}
+	}
+}
+
+func TestParsingGeneratedBoards(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for _, name := range Names() {
+		algorithm, _ := Lookup(name)
+		b := algorithm.Generate(9, 7, rng)
+		PlacementFunc(placeRandomly).Place(b, rng)
+		for _, text := range []string{b.String(), b.PrettyString()} {
+			parsed, error := board.Parse(text)
+			if error != nil {
+				t.Fatalf("Unable to parse a board of %s:\n%s\n%v", name, text, error)
+			}
+			if parsed.String() != b.String() {
+				t.Errorf("Board of %s parsed as\n%s\nexpected\n%s",
+					name, parsed.String(), b.String())
+			}
+		}
+	}
+}
+
+func TestLookingUpUnknownAlgorithm(t *testing.T) {
+	if _, error := Lookup("no-such-algorithm"); error == nil {
+		t.Errorf("Looking up an unknown algorithm succeeded")
+	}
+}
+
+func spanningTreeDistribution(t *testing.T, algorithm Algorithm, rng *rand.Rand,
+	width, height, samples int) []int {
+	trees := testutil.SpanningTrees(width, height)
+	treeIndices := make(map[uint64]int)
+	for i, tree := range trees {
+		treeIndices[tree] = i
+	}
+	edges := testutil.GridEdges(width, height)
+	counts := make([]int, len(trees))
+	for i := 0; i < samples; i++ {
+		b := algorithm.Generate(width, height, rng)
+		tree := uint64(0)
+		for j, edge := range edges {
+			dir := board.S
+			if edge.To.X > edge.From.X {
+				dir = board.E
+			}
+			if b.At(edge.From.X, edge.From.Y).Direction()&dir != 0 {
+				tree |= 1 << uint(j)
+			}
+		}
+		index, ok := treeIndices[tree]
+		if !ok {
+			t.Fatalf("Generated board is not a spanning tree:\n%v", b)
+		}
+		counts[index]++
+	}
+	return counts
+}
+
+func TestWilsonUniformity(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	wilson, _ := Lookup("wilson")
+	counts := spanningTreeDistribution(t, wilson, rng, 3, 3, 192*50)
+	if p := testutil.ChiSquareUniformity(counts); p < 0.001 {
+		t.Errorf("Distribution of mazes generated by Wilson's algorithm "+
+			"is not uniform, p-value is %v. Counts: %v", p, counts)
+	}
+}
+
+func TestUniformityCheckDetectsBias(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	counts := spanningTreeDistribution(t, GrowingTree{Newest}, rng, 3, 3, 192*50)
+	if p := testutil.ChiSquareUniformity(counts); p >= 0.001 {
+		t.Errorf("Distribution of mazes generated by the backtracker "+
+			"is considered uniform, p-value is %v. Counts: %v", p, counts)
+	}
+}
@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"board"
+	"image"
+	"rand"
+)
+
+// Braid removes the given fraction of dead ends of the board by opening one
+// of their walls, which turns a perfect maze into one with loops. Walls to
+// other dead ends are opened preferably, so that a single passage removes two
+// dead ends at once.
+func Braid(b board.Board, fraction float64, rng *rand.Rand) {
+	rect := boardRect(b)
+	deadEnds := make([]image.Point, 0)
+	for y := 0; y < b.Height(); y++ {
+		for x := 0; x < b.Width(); x++ {
+			if isDeadEnd(b, image.Pt(x, y)) {
+				deadEnds = append(deadEnds, image.Pt(x, y))
+			}
+		}
+	}
+
+	count := int(fraction*float64(len(deadEnds)) + 0.5)
+	if count < 0 {
+		count = 0
+	} else if count > len(deadEnds) {
+		count = len(deadEnds)
+	}
+	for _, i := range rng.Perm(len(deadEnds))[:count] {
+		p := deadEnds[i]
+		// The dead end might have been already removed by opening a passage
+		// from one of its neighbours.
+		if !isDeadEnd(b, p) {
+			continue
+		}
+		dir := b.At(p.X, p.Y).Direction()
+		walled := func(q image.Point) bool { return dir&directionTo(p, q) == 0 }
+		possibleDirections := filterDirections(p, rect, walled)
+		if len(possibleDirections) == 0 {
+			continue
+		}
+		preferredDirections := filterDirections(p, rect, func(q image.Point) bool {
+			return walled(q) && isDeadEnd(b, q)
+		})
+		if len(preferredDirections) > 0 {
+			possibleDirections = preferredDirections
+		}
+		carve(b, p, randomDirection(rng, possibleDirections), nopObserver{})
+	}
+}
+
+func isDeadEnd(b board.Board, p image.Point) bool {
+	return len(b.At(p.X, p.Y).Direction().Decompose()) == 1
+}
+
+func directionTo(p, q image.Point) board.Direction {
+	for _, dir := range directions {
+		if neighbour(p, dir).Eq(q) {
+			return dir
+		}
+	}
+	return board.None
+}
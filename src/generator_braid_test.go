@@ -0,0 +1,55 @@
+package generator
+
+import (
+	"board"
+	"rand"
+	"testing"
+	"testutil"
+)
+
+func TestBraiding(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	const width, height = 12, 8
+	for _, fraction := range []float64{0, 0.25, 0.5, 1} {
+		b := Generate(width, height, rng)
+		deadEnds := b.DeadEnds()
+		Braid(b, fraction, rng)
+		maxDeadEnds := deadEnds - int(fraction*float64(deadEnds)+0.5)
+		if b.DeadEnds() > maxDeadEnds || (fraction == 0 && b.DeadEnds() != deadEnds) {
+			t.Errorf("Braiding %v of %d dead ends left %d of them",
+				fraction, deadEnds, b.DeadEnds())
+		}
+		if !b.Validate() {
+			t.Fatalf("Braided board doesn't validate:\n%v", b)
+		}
+		visitMatrix, error := b.Walk(false)
+		if error != nil {
+			t.Fatalf("Unexpected error: %v. Braided board:\n%v", error, b)
+		}
+		if !testutil.MatricesEqual(trueMatrix(width, height), visitMatrix) {
+			t.Errorf("Braided board is not fully connected:\n%v", b)
+		}
+		if (b.Loops() > 0) != (fraction > 0) {
+			t.Errorf("Braiding %v of dead ends resulted in %d loops",
+				fraction, b.Loops())
+		}
+		solution, error := b.Walk(true)
+		if error != nil {
+			t.Fatalf("Unable to solve braided board: %v\n%v", error, b)
+		}
+		entrance, exit := *b.Entrance(), *b.Exit()
+		if !solution[entrance.Y][entrance.X] || !solution[exit.Y][exit.X] {
+			t.Errorf("Solution of braided board doesn't lead from %v to %v",
+				entrance, exit)
+		}
+	}
+}
+
+func TestBraidingSingleField(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	b := Generate(1, 1, rng)
+	Braid(b, 1, rng)
+	if b.At(0, 0).Direction() != board.N|board.S {
+		t.Errorf("Braiding changed a single field to %v", b.At(0, 0).Direction())
+	}
+}
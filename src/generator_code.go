@@ -0,0 +1,264 @@
+package generator
+
+import (
+	"board"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"os"
+	"rand"
+	"strings"
+)
+
+// Code holds everything needed to generate a maze again, so that players can
+// share the maze as a short string such as "040183ZAP21Z1R5FZZ5J82G0FVWG".
+type Code struct {
+	Algorithm     string
+	Width, Height int
+	Seed          int64
+	// Braid is the fraction of dead ends removed. Only whole percents can
+	// be encoded.
+	Braid float64
+	// Placement is a placement spec as accepted by ParsePlacement, or empty to
+	// keep the placement of the algorithm.
+	Placement string
+}
+
+const codeVersion = 1
+
+// codeAlgorithms and codePlacements number the algorithms and placements in
+// codes. Names may only be appended, as the numbers of codes shared already
+// must not change.
+var (
+	codeAlgorithms = []string{
+		"growing-tree", "backtracker", "prim", "kruskal", "wilson",
+		"aldous-broder", "hunt-and-kill", "eller", "binary-tree", "sidewinder",
+		"recursive-division",
+	}
+	codePlacements = []string{"", "top-bottom", "corners", "random", "diameter", "fixed"}
+)
+
+// maxCodeSize limits the width and height of boards in codes, so that a
+// mistyped or forged code can't make the generator allocate a huge board.
+const maxCodeSize = 1 << 11
+
+// codeAlphabet is Crockford's base32 alphabet, which leaves out letters easily
+// mistaken for digits.
+const codeAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Encode packs the version, algorithm, size, seed and options as variable
+// length integers, followed by a 16 bit checksum catching typos, and writes
+// them in base32.
+func (self Code) Encode() (string, os.Error) {
+	algorithm := indexOf(codeAlgorithms, self.Algorithm)
+	if algorithm < 0 {
+		return "", os.NewError("Algorithm " + self.Algorithm + " has no code")
+	}
+	if self.Width < 1 || self.Width > maxCodeSize || self.Height < 1 || self.Height > maxCodeSize {
+		return "", fmt.Errorf("Invalid board size: %dx%d", self.Width, self.Height)
+	}
+	braid := math.Floor(self.Braid*100 + 0.5)
+	if braid < 0 || braid > 100 || math.Fabs(braid-self.Braid*100) > 1e-9 {
+		return "", fmt.Errorf("Braid %v is not a whole percentage", self.Braid)
+	}
+	placementName, fixed := self.Placement, Fixed{}
+	if strings.HasPrefix(placementName, "fixed:") {
+		placement, error := ParsePlacement(self.Placement)
+		if error != nil {
+			return "", error
+		}
+		placementName, fixed = "fixed", placement.(Fixed)
+	}
+	placement := indexOf(codePlacements, placementName)
+	if placement < 0 {
+		return "", os.NewError("Unknown placement " + self.Placement)
+	}
+
+	data := []byte{codeVersion}
+	for _, value := range []uint64{uint64(algorithm), uint64(self.Width),
+		uint64(self.Height), zigzag(self.Seed), uint64(braid), uint64(placement)} {
+		data = appendUvarint(data, value)
+	}
+	if placementName == "fixed" {
+		for _, value := range []int{fixed.Entrance.X, fixed.Entrance.Y, fixed.Exit.X, fixed.Exit.Y} {
+			data = appendUvarint(data, zigzag(int64(value)))
+		}
+	}
+	checksum := crc32.ChecksumIEEE(data)
+	data = append(data, uint8(checksum>>8), uint8(checksum))
+	return encodeBase32(data), nil
+}
+
+// DecodeCode reads a code written by Encode. Letters may be of any case, and
+// the letters I, L and O are read as the digits they resemble. Dashes and
+// spaces are ignored.
+func DecodeCode(code string) (Code, os.Error) {
+	var self Code
+	data, error := decodeBase32(code)
+	if error != nil {
+		return self, error
+	}
+	if len(data) < 3 {
+		return self, os.NewError("Maze code is too short")
+	}
+	payload := data[:len(data)-2]
+	checksum := crc32.ChecksumIEEE(payload)
+	if data[len(data)-2] != uint8(checksum>>8) || data[len(data)-1] != uint8(checksum) {
+		return self, os.NewError("Maze code is mistyped")
+	}
+	if payload[0] != codeVersion {
+		return self, fmt.Errorf("Unsupported version %d of maze codes", payload[0])
+	}
+	values := make([]uint64, 0, 10)
+	for rest := payload[1:]; len(rest) > 0; {
+		value, n := readUvarint(rest)
+		if n == 0 {
+			return self, os.NewError("Maze code is malformed")
+		}
+		values, rest = append(values, value), rest[n:]
+	}
+	if len(values) < 6 {
+		return self, os.NewError("Maze code is malformed")
+	}
+	algorithm, placement := values[0], values[5]
+	switch {
+	case algorithm >= uint64(len(codeAlgorithms)):
+		return self, fmt.Errorf("Unknown algorithm %d in maze code", algorithm)
+	case placement >= uint64(len(codePlacements)):
+		return self, fmt.Errorf("Unknown placement %d in maze code", placement)
+	case values[1] < 1 || values[1] > maxCodeSize || values[2] < 1 || values[2] > maxCodeSize:
+		return self, os.NewError("Invalid board size in maze code")
+	case values[4] > 100:
+		return self, os.NewError("Invalid braid in maze code")
+	case codePlacements[placement] == "fixed" && len(values) != 10,
+		codePlacements[placement] != "fixed" && len(values) != 6:
+		return self, os.NewError("Maze code is malformed")
+	}
+	self.Algorithm = codeAlgorithms[algorithm]
+	self.Width, self.Height = int(values[1]), int(values[2])
+	self.Seed = unzigzag(values[3])
+	self.Braid = float64(values[4]) / 100
+	self.Placement = codePlacements[placement]
+	if self.Placement == "fixed" {
+		self.Placement = fmt.Sprintf("fixed:%d,%d:%d,%d", unzigzag(values[6]),
+			unzigzag(values[7]), unzigzag(values[8]), unzigzag(values[9]))
+	}
+	return self, nil
+}
+
+// Generate generates the maze of the code, the same way as generating it with
+// the algorithm, seed and options given separately.
+func (self Code) Generate() (board.Board, os.Error) {
+	algorithm, error := Lookup(self.Algorithm)
+	if error != nil {
+		return nil, error
+	}
+	rng := rand.New(rand.NewSource(self.Seed))
+	b := algorithm.Generate(self.Width, self.Height, rng)
+	if b == nil {
+		return nil, fmt.Errorf("Invalid board size: %dx%d", self.Width, self.Height)
+	}
+	if self.Braid > 0 {
+		Braid(b, self.Braid, rng)
+	}
+	if self.Placement != "" {
+		placement, error := ParsePlacement(self.Placement)
+		if error != nil {
+			return nil, error
+		}
+		if error = placement.Place(b, rng); error != nil {
+			return nil, error
+		}
+	}
+	return b, nil
+}
+
+// zigzag maps signed integers to unsigned ones, so that small negative
+// numbers stay small.
+func zigzag(value int64) uint64 {
+	return uint64(value<<1) ^ uint64(value>>63)
+}
+
+func unzigzag(value uint64) int64 {
+	return int64(value>>1) ^ -int64(value&1)
+}
+
+// appendUvarint appends the value in groups of seven bits, from the lowest
+// one, with the highest bit set in all the bytes but the last one.
+func appendUvarint(data []byte, value uint64) []byte {
+	for value >= 0x80 {
+		data = append(data, uint8(value)|0x80)
+		value >>= 7
+	}
+	return append(data, uint8(value))
+}
+
+// readUvarint returns the value and the number of bytes read, which is zero
+// if the data ends early or the value doesn't fit into 64 bits.
+func readUvarint(data []byte) (uint64, int) {
+	var value uint64
+	for i, b := range data {
+		if i == 10 || i == 9 && b > 1 {
+			return 0, 0
+		}
+		value |= uint64(b&0x7f) << uint(7*i)
+		if b < 0x80 {
+			return value, i + 1
+		}
+	}
+	return 0, 0
+}
+
+func encodeBase32(data []byte) string {
+	var buf []byte
+	var bits uint
+	var n uint
+	for _, b := range data {
+		bits, n = bits<<8|uint(b), n+8
+		for n >= 5 {
+			n -= 5
+			buf = append(buf, codeAlphabet[bits>>n&31])
+		}
+	}
+	if n > 0 {
+		buf = append(buf, codeAlphabet[bits<<(5-n)&31])
+	}
+	return string(buf)
+}
+
+func decodeBase32(code string) ([]byte, os.Error) {
+	code = strings.ToUpper(code)
+	var data []byte
+	var bits uint
+	var n uint
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch c {
+		case '-', ' ':
+			continue
+		case 'I', 'L':
+			c = '1'
+		case 'O':
+			c = '0'
+		}
+		value := strings.Index(codeAlphabet, string(c))
+		if value < 0 {
+			return nil, fmt.Errorf("Invalid character %q in maze code", code[i])
+		}
+		bits, n = bits<<5|uint(value), n+5
+		if n >= 8 {
+			n -= 8
+			data = append(data, uint8(bits>>n))
+		}
+	}
+	return data, nil
+}
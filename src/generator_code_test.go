@@ -0,0 +1,96 @@
+package generator
+
+import (
+	"hash/crc32"
+	"rand"
+	"strings"
+	"testing"
+)
+
+func TestMazeCodeRoundTrip(t *testing.T) {
+	testCases := []Code{
+		{"growing-tree", 20, 10, 1318427412345678901, 0, ""},
+		{"wilson", 1, 1, 0, 0.5, "diameter"},
+		{"recursive-division", 300, 7, -42, 1, "fixed:0,3:299,0"},
+		{"eller", 12, 8, 7, 0.25, "top-bottom"},
+	}
+	for _, code := range testCases {
+		text, error := code.Encode()
+		if error != nil {
+			t.Fatalf("Unable to encode %v: %v", code, error)
+		}
+		if len(text) > 32 {
+			t.Errorf("Code %s of %v is too long", text, code)
+		}
+		for _, variant := range []string{text, strings.ToLower(text),
+			strings.Replace(strings.Replace(text, "1", "l", -1), "0", "O", -1)} {
+			decoded, error := DecodeCode(variant)
+			if error != nil {
+				t.Fatalf("Unable to decode %s of %v: %v", variant, code, error)
+			}
+			if decoded.Algorithm != code.Algorithm || decoded.Width != code.Width ||
+				decoded.Height != code.Height || decoded.Seed != code.Seed ||
+				decoded.Braid != code.Braid || decoded.Placement != code.Placement {
+				t.Errorf("Code %s was decoded as %v, expected %v", variant, decoded, code)
+			}
+		}
+	}
+}
+
+func TestGeneratingFromMazeCode(t *testing.T) {
+	code := Code{"backtracker", 12, 8, 99, 0.3, "random"}
+	text, _ := code.Encode()
+	decoded, error := DecodeCode(text)
+	if error != nil {
+		t.Fatalf("Unable to decode %s: %v", text, error)
+	}
+	b, error := decoded.Generate()
+	if error != nil {
+		t.Fatalf("Unable to generate the maze of %s: %v", text, error)
+	}
+	rng := rand.New(rand.NewSource(99))
+	expected := AlgorithmFunc(backtracker).Generate(12, 8, rng)
+	Braid(expected, 0.3, rng)
+	PlacementFunc(placeRandomly).Place(expected, rng)
+	if b.String() != expected.String() {
+		t.Errorf("Maze of %s is\n%s\nexpected\n%s", text, b.String(), expected.String())
+	}
+}
+
+func TestInvalidMazeCodes(t *testing.T) {
+	for _, code := range []Code{
+		{"no-such-algorithm", 5, 5, 0, 0, ""},
+		{"prim", 0, 5, 0, 0, ""},
+		{"prim", 5, maxCodeSize + 1, 0, 0, ""},
+		{"prim", 5, 5, 0, 0.333, ""},
+		{"prim", 5, 5, 0, 0, "nowhere"},
+	} {
+		if text, error := code.Encode(); error == nil {
+			t.Errorf("Invalid %v was encoded as %s", code, text)
+		}
+	}
+	valid, _ := Code{"kruskal", 15, 9, 123456, 0, "corners"}.Encode()
+	for i := range valid {
+		for _, c := range "0Z" {
+			mistyped := valid[:i] + string(c) + valid[i+1:]
+			if mistyped == valid || i == len(valid)-1 {
+				continue
+			}
+			if _, error := DecodeCode(mistyped); error == nil {
+				t.Errorf("Mistyped code %s was decoded", mistyped)
+			}
+		}
+	}
+	// A code with a valid checksum for a board too large to generate.
+	data := []byte{codeVersion}
+	for _, value := range []uint64{0, maxCodeSize + 1, 1 << 30, 0, 0, 0} {
+		data = appendUvarint(data, value)
+	}
+	checksum := crc32.ChecksumIEEE(data)
+	huge := encodeBase32(append(data, uint8(checksum>>8), uint8(checksum)))
+	for _, text := range []string{"", "U", "0", valid[:len(valid)-3], valid + "00", huge} {
+		if _, error := DecodeCode(text); error == nil {
+			t.Errorf("Invalid code %q was decoded", text)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"board"
+	"image"
+	"rand"
+)
+
+func recursiveDivision(width, height int, rng *rand.Rand, observer Observer) board.Board {
+	b := board.New(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x+1 < width {
+				carve(b, image.Pt(x, y), board.E, observer)
+			}
+			if y+1 < height {
+				carve(b, image.Pt(x, y), board.S, observer)
+			}
+		}
+	}
+
+	// The chambers are kept on an explicit stack, so that long and narrow
+	// boards don't result in a deep recursion.
+	chambers := []image.Rectangle{boardRect(b)}
+	for len(chambers) > 0 {
+		chamber := chambers[len(chambers)-1]
+		chambers = chambers[:len(chambers)-1]
+		w, h := chamber.Dx(), chamber.Dy()
+		if w < 2 && h < 2 {
+			continue
+		}
+		// The wall is put below the row, or to the right of the column,
+		// at wallAt, leaving a door in it.
+		horizontal := h > w || (h == w && rng.Intn(2) == 0)
+		if horizontal {
+			wallAt := chamber.Min.Y + rng.Intn(h-1)
+			door := chamber.Min.X + rng.Intn(w)
+			for x := chamber.Min.X; x < chamber.Max.X; x++ {
+				if x != door {
+					wall(b, image.Pt(x, wallAt), board.S, observer)
+				}
+			}
+			chambers = append(chambers,
+				image.Rect(chamber.Min.X, chamber.Min.Y, chamber.Max.X, wallAt+1),
+				image.Rect(chamber.Min.X, wallAt+1, chamber.Max.X, chamber.Max.Y))
+		} else {
+			wallAt := chamber.Min.X + rng.Intn(w-1)
+			door := chamber.Min.Y + rng.Intn(h)
+			for y := chamber.Min.Y; y < chamber.Max.Y; y++ {
+				if y != door {
+					wall(b, image.Pt(wallAt, y), board.E, observer)
+				}
+			}
+			chambers = append(chambers,
+				image.Rect(chamber.Min.X, chamber.Min.Y, wallAt+1, chamber.Max.Y),
+				image.Rect(wallAt+1, chamber.Min.Y, chamber.Max.X, chamber.Max.Y))
+		}
+	}
+
+	openEntranceAndExit(b, rng)
+	return b
+}
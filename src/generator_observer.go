@@ -0,0 +1,34 @@
+package generator
+
+import (
+	"board"
+	"image"
+	"rand"
+)
+
+// Observer is notified of the progress of a generation algorithm, for example
+// to animate it.
+type Observer interface {
+	// Carved is called after a passage from the field in the given direction
+	// was opened.
+	Carved(p image.Point, dir board.Direction)
+	// Walled is called after a passage from the field in the given direction
+	// was closed.
+	Walled(p image.Point, dir board.Direction)
+	// Frontier is called with the fields the algorithm currently grows the
+	// maze from. The slice is only valid during the call.
+	Frontier(fields []image.Point)
+}
+
+// ObservableAlgorithm is an algorithm that can report its progress to an
+// observer. All the registered algorithms are observable.
+type ObservableAlgorithm interface {
+	Algorithm
+	GenerateObserved(width, height int, rng *rand.Rand, observer Observer) board.Board
+}
+
+type nopObserver struct{}
+
+func (self nopObserver) Carved(p image.Point, dir board.Direction) {}
+func (self nopObserver) Walled(p image.Point, dir board.Direction) {}
+func (self nopObserver) Frontier(fields []image.Point)             {}
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"board"
+	"image"
+	"rand"
+	"testing"
+)
+
+// replayingObserver carves and walls the passages it is notified of on a board
+// of its own.
+type replayingObserver struct {
+	b                  board.Board
+	events             int
+	frontierOutOfBoard bool
+}
+
+func (self *replayingObserver) Carved(p image.Point, dir board.Direction) {
+	carve(self.b, p, dir, nopObserver{})
+	self.events++
+}
+
+func (self *replayingObserver) Walled(p image.Point, dir board.Direction) {
+	wall(self.b, p, dir, nopObserver{})
+	self.events++
+}
+
+func (self *replayingObserver) Frontier(fields []image.Point) {
+	for _, p := range fields {
+		if !p.In(boardRect(self.b)) {
+			self.frontierOutOfBoard = true
+		}
+	}
+}
+
+func TestObservingAlgorithms(t *testing.T) {
+	const width, height = 9, 7
+	rng := rand.New(rand.NewSource(0))
+	for _, name := range Names() {
+		algorithm, _ := Lookup(name)
+		observable, ok := algorithm.(ObservableAlgorithm)
+		if !ok {
+			t.Errorf("Algorithm %s is not observable", name)
+			continue
+		}
+		observer := &replayingObserver{b: board.New(width, height)}
+		b := observable.GenerateObserved(width, height, rng, observer)
+		if observer.events < width*height-1 {
+			t.Errorf("Algorithm %s reported only %d events", name, observer.events)
+		}
+		if observer.frontierOutOfBoard {
+			t.Errorf("Algorithm %s reported a frontier outside of the board", name)
+		}
+		rect := boardRect(b)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				p := image.Pt(x, y)
+				for _, dir := range directions {
+					if !neighbour(p, dir).In(rect) {
+						continue
+					}
+					generated := b.At(x, y).Direction()&dir != 0
+					replayed := observer.b.At(x, y).Direction()&dir != 0
+					if generated != replayed {
+						t.Errorf("Algorithm %s: passage %v from %v is %v, "+
+							"but the observer saw %v", name, dir, p,
+							generated, replayed)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package generator
+
+import (
+	"board"
+	"fmt"
+	"image"
+	"os"
+	"rand"
+	"sort"
+	"strings"
+)
+
+// Placement decides where the entrance and exit of a generated board are.
+// It closes any openings in the border of the board and opens the border
+// walls of the new entrance and exit.
+type Placement interface {
+	Place(b board.Board, rng *rand.Rand) os.Error
+}
+
+type PlacementFunc func(b board.Board, rng *rand.Rand) os.Error
+
+func (self PlacementFunc) Place(b board.Board, rng *rand.Rand) os.Error {
+	return self(b, rng)
+}
+
+var placements map[string]Placement = map[string]Placement{
+	"top-bottom": PlacementFunc(placeTopBottom),
+	"corners":    PlacementFunc(placeInCorners),
+	"random":     PlacementFunc(placeRandomly),
+	"diameter":   PlacementFunc(placeOnDiameter),
+}
+
+// Fixed places the entrance and exit at given fields of the border.
+type Fixed struct {
+	Entrance, Exit image.Point
+}
+
+func (self Fixed) Place(b board.Board, rng *rand.Rand) os.Error {
+	return setEntranceAndExit(b, self.Entrance, self.Exit)
+}
+
+// ParsePlacement parses a placement name, or a specification of fixed
+// entrance and exit coordinates, such as "fixed:0,0:9,9".
+func ParsePlacement(spec string) (Placement, os.Error) {
+	if strings.HasPrefix(spec, "fixed:") {
+		var placement Fixed
+		_, error := fmt.Sscanf(spec, "fixed:%d,%d:%d,%d",
+			&placement.Entrance.X, &placement.Entrance.Y,
+			&placement.Exit.X, &placement.Exit.Y)
+		if error != nil {
+			return nil, os.NewError("Invalid fixed placement " + spec)
+		}
+		return placement, nil
+	}
+	placement, ok := placements[spec]
+	if !ok {
+		return nil, os.NewError("Unknown placement " + spec)
+	}
+	return placement, nil
+}
+
+func PlacementNames() []string {
+	names := make([]string, 0, len(placements)+1)
+	for name := range placements {
+		names = append(names, name)
+	}
+	names = append(names, "fixed:x,y:x,y")
+	sort.Strings(names)
+	return names
+}
+
+func placeTopBottom(b board.Board, rng *rand.Rand) os.Error {
+	return setEntranceAndExit(b, image.Pt(rng.Intn(b.Width()), 0),
+		image.Pt(rng.Intn(b.Width()), b.Height()-1))
+}
+
+func placeInCorners(b board.Board, rng *rand.Rand) os.Error {
+	return setEntranceAndExit(b, image.Pt(0, 0),
+		image.Pt(b.Width()-1, b.Height()-1))
+}
+
+func placeRandomly(b board.Board, rng *rand.Rand) os.Error {
+	border := borderFields(b)
+	if len(border) == 1 {
+		return setEntranceAndExit(b, border[0], border[0])
+	}
+	picked := rng.Perm(len(border))
+	return setEntranceAndExit(b, border[picked[0]], border[picked[1]])
+}
+
+// placeOnDiameter places the entrance and exit at the two border fields
+// farthest apart. The first pass finds the border field farthest from an
+// arbitrary one, which is an end of the longest path; the second pass finds
+// the other end. On perfect mazes, this gives the exact diameter.
+func placeOnDiameter(b board.Board, rng *rand.Rand) os.Error {
+	border := borderFields(b)
+	entrance, error := farthestBorderField(b, border, border[0])
+	if error != nil {
+		return error
+	}
+	exit, error := farthestBorderField(b, border, entrance)
+	if error != nil {
+		return error
+	}
+	return setEntranceAndExit(b, entrance, exit)
+}
+
+func farthestBorderField(b board.Board, border []image.Point,
+	source image.Point) (image.Point, os.Error) {
+	distanceMap, error := b.Distances(source, nil)
+	if error != nil {
+		return source, error
+	}
+	farthest, max := source, 0
+	for _, p := range border {
+		if distance := distanceMap.Distances[p.Y][p.X]; distance > max {
+			farthest, max = p, distance
+		}
+	}
+	return farthest, nil
+}
+
+func borderFields(b board.Board) []image.Point {
+	width, height := b.Width(), b.Height()
+	border := make([]image.Point, 0, 2*(width+height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x == 0 || y == 0 || x == width-1 || y == height-1 {
+				border = append(border, image.Pt(x, y))
+			}
+		}
+	}
+	return border
+}
+
+func setEntranceAndExit(b board.Board, entrance, exit image.Point) os.Error {
+	rect := boardRect(b)
+	for _, p := range []image.Point{entrance, exit} {
+		if !p.In(rect) || len(filterDirections(p, rect, anyPoint)) == 4 {
+			return fmt.Errorf("Field %v is not on the border of the board", p)
+		}
+	}
+	outside := func(p image.Point, dir board.Direction) bool {
+		return !neighbour(p, dir).In(rect)
+	}
+	for _, p := range borderFields(b) {
+		field := b.At(p.X, p.Y)
+		for _, dir := range directions {
+			if outside(p, dir) {
+				field.SetDirection(field.Direction() &^ dir)
+			}
+		}
+	}
+	// Prefer opening the entrance to the north and the exit to the south.
+	for _, opening := range []struct {
+		Field     image.Point
+		Preferred board.Direction
+	}{{entrance, board.N}, {exit, board.S}} {
+		p, dir := opening.Field, opening.Preferred
+		for i := 0; !outside(p, dir); i++ {
+			dir = directions[i]
+		}
+		b.At(p.X, p.Y).AddDirection(dir)
+	}
+	*b.Entrance() = entrance
+	*b.Exit() = exit
+	return nil
+}
@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"board"
+	"image"
+	"rand"
+	"testing"
+	"testutil"
+)
+
+func countOpenings(b board.Board) int {
+	openings := 0
+	rect := boardRect(b)
+	for y := 0; y < b.Height(); y++ {
+		for x := 0; x < b.Width(); x++ {
+			p := image.Pt(x, y)
+			for _, dir := range b.At(x, y).Direction().Decompose() {
+				if !neighbour(p, dir).In(rect) {
+					openings++
+				}
+			}
+		}
+	}
+	return openings
+}
+
+func TestPlacements(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	specs := []string{"top-bottom", "corners", "random", "diameter", "fixed:0,2:4,0"}
+	for _, spec := range specs {
+		placement, error := ParsePlacement(spec)
+		if error != nil {
+			t.Fatalf("Unable to parse placement %s: %v", spec, error)
+		}
+		for _, size := range []image.Point{{5, 3}, {1, 1}, {6, 4}} {
+			if spec == "fixed:0,2:4,0" && !size.Eq(image.Pt(5, 3)) {
+				continue
+			}
+			b := Generate(size.X, size.Y, rng)
+			Braid(b, 0.5, rng)
+			if error := placement.Place(b, rng); error != nil {
+				t.Errorf("Unable to place %s on a %v board: %v", spec, size, error)
+				continue
+			}
+			visitMatrix, error := b.Walk(false)
+			if error != nil {
+				t.Errorf("Unexpected error after placement %s: %v\n%v",
+					spec, error, b)
+				continue
+			}
+			if !testutil.MatricesEqual(trueMatrix(size.X, size.Y), visitMatrix) {
+				t.Errorf("Board is not fully connected after placement %s:\n%v",
+					spec, b)
+			}
+			if openings := countOpenings(b); openings != 2 {
+				t.Errorf("Board has %d openings after placement %s:\n%v",
+					openings, spec, b)
+			}
+		}
+	}
+}
+
+func TestFixedPlacement(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	b := Generate(5, 3, rng)
+	placement, _ := ParsePlacement("fixed:0,1:4,2")
+	if error := placement.Place(b, rng); error != nil {
+		t.Fatalf("Unexpected error: %v", error)
+	}
+	if !b.Entrance().Eq(image.Pt(0, 1)) || !b.Exit().Eq(image.Pt(4, 2)) {
+		t.Errorf("Entrance and exit are %v and %v, expected (0,1) and (4,2)",
+			b.Entrance(), b.Exit())
+	}
+	if b.At(0, 1).Direction()&board.W == 0 || b.At(4, 2).Direction()&board.S == 0 {
+		t.Errorf("Entrance and exit are not open:\n%v", b)
+	}
+	placement, _ = ParsePlacement("fixed:2,1:4,2")
+	if error := placement.Place(b, rng); error == nil {
+		t.Errorf("Entrance placed inside the board")
+	}
+	for _, spec := range []string{"fixed:1,2", "middle", ""} {
+		if _, error := ParsePlacement(spec); error == nil {
+			t.Errorf("Parsing placement %q succeeded", spec)
+		}
+	}
+}
+
+func TestDiameterPlacement(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	placement, _ := ParsePlacement("diameter")
+	for i := 0; i < 20; i++ {
+		b := Generate(7, 5, rng)
+		placement.Place(b, rng)
+		path, error := b.Solve()
+		if error != nil {
+			t.Fatalf("Unable to solve the board: %v", error)
+		}
+		border := borderFields(b)
+		for _, p := range border {
+			distanceMap, _ := b.Distances(p, nil)
+			for _, q := range border {
+				if distanceMap.Distances[q.Y][q.X] > path.Length() {
+					t.Fatalf("Path from %v to %v is longer than the solution:\n%v",
+						p, q, b)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package generator
+
+import (
+	"board"
+	"image"
+	"rand"
+)
+
+func prim(width, height int, rng *rand.Rand, observer Observer) board.Board {
+	b := board.New(width, height)
+	rect := boardRect(b)
+	inMaze := newMatrix(width, height)
+	inFrontier := newMatrix(width, height)
+	isInMaze := func(q image.Point) bool { return inMaze[q.Y][q.X] }
+	frontier := make([]image.Point, 0)
+	addToMaze := func(p image.Point) {
+		inMaze[p.Y][p.X] = true
+		for _, dir := range directions {
+			q := neighbour(p, dir)
+			if q.In(rect) && !inMaze[q.Y][q.X] && !inFrontier[q.Y][q.X] {
+				inFrontier[q.Y][q.X] = true
+				frontier = append(frontier, q)
+			}
+		}
+	}
+	addToMaze(randomPoint(rng, rect))
+
+	for len(frontier) > 0 {
+		observer.Frontier(frontier)
+		i := rng.Intn(len(frontier))
+		p := frontier[i]
+		last := len(frontier) - 1
+		frontier[i] = frontier[last]
+		frontier = frontier[:last]
+		carve(b, p, randomDirection(rng, filterDirections(p, rect, isInMaze)), observer)
+		addToMaze(p)
+	}
+
+	openEntranceAndExit(b, rng)
+	return b
+}
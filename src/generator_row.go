@@ -0,0 +1,50 @@
+package generator
+
+import (
+	"board"
+	"image"
+	"rand"
+)
+
+func binaryTree(width, height int, rng *rand.Rand, observer Observer) board.Board {
+	b := board.New(width, height)
+	rect := boardRect(b)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			p := image.Pt(x, y)
+			possibleDirections := make([]board.Direction, 0, 2)
+			for _, dir := range []board.Direction{board.N, board.W} {
+				if neighbour(p, dir).In(rect) {
+					possibleDirections = append(possibleDirections, dir)
+				}
+			}
+			if len(possibleDirections) > 0 {
+				carve(b, p, randomDirection(rng, possibleDirections), observer)
+			}
+		}
+	}
+
+	openEntranceAndExit(b, rng)
+	return b
+}
+
+func sidewinder(width, height int, rng *rand.Rand, observer Observer) board.Board {
+	b := board.New(width, height)
+	for x := 0; x+1 < width; x++ {
+		carve(b, image.Pt(x, 0), board.E, observer)
+	}
+	for y := 1; y < height; y++ {
+		runStart := 0
+		for x := 0; x < width; x++ {
+			if x+1 == width || rng.Intn(2) == 0 {
+				carve(b, image.Pt(runStart+rng.Intn(x-runStart+1), y), board.N, observer)
+				runStart = x + 1
+			} else {
+				carve(b, image.Pt(x, y), board.E, observer)
+			}
+		}
+	}
+
+	openEntranceAndExit(b, rng)
+	return b
+}
@@ -0,0 +1,213 @@
+package generator
+
+import (
+	"board"
+	"image"
+	"os"
+	"rand"
+)
+
+type disjointSets []int
+
+func newDisjointSets(size int) disjointSets {
+	sets := make(disjointSets, size)
+	for i := range sets {
+		sets[i] = i
+	}
+	return sets
+}
+
+func (self disjointSets) find(i int) int {
+	for self[i] != i {
+		self[i] = self[self[i]]
+		i = self[i]
+	}
+	return i
+}
+
+func (self disjointSets) union(i, j int) bool {
+	i, j = self.find(i), self.find(j)
+	if i == j {
+		return false
+	}
+	self[j] = i
+	return true
+}
+
+type passage struct {
+	From image.Point
+	Dir  board.Direction
+}
+
+func kruskal(width, height int, rng *rand.Rand, observer Observer) board.Board {
+	b := board.New(width, height)
+	passages := make([]passage, 0, 2*width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x+1 < width {
+				passages = append(passages, passage{image.Pt(x, y), board.E})
+			}
+			if y+1 < height {
+				passages = append(passages, passage{image.Pt(x, y), board.S})
+			}
+		}
+	}
+	sets := newDisjointSets(width * height)
+	for _, i := range rng.Perm(len(passages)) {
+		p := passages[i]
+		q := neighbour(p.From, p.Dir)
+		if sets.union(p.From.Y*width+p.From.X, q.Y*width+q.X) {
+			carve(b, p.From, p.Dir, observer)
+		}
+	}
+
+	openEntranceAndExit(b, rng)
+	return b
+}
+
+// ellerRows generates a maze row by row using Eller's algorithm, keeping
+// only the current row in memory. Each field of the current row belongs to
+// a set of fields connected through the rows generated so far; set
+// identifiers are kept within 1..width so that they can index slices.
+type ellerRows struct {
+	width, height, y int
+	entrance, exit   image.Point
+	rng              *rand.Rand
+	sets             []int
+	openNorth        []bool
+}
+
+// NewEllerRows returns a source of rows of a maze generated with Eller's
+// algorithm. Since the rows are generated on demand, the height of the maze
+// is only limited by the time one is willing to wait.
+func NewEllerRows(width, height int, rng *rand.Rand) board.Rows {
+	if width < 1 || height < 1 {
+		return nil
+	}
+	return &ellerRows{
+		width:     width,
+		height:    height,
+		entrance:  image.Pt(rng.Intn(width), 0),
+		exit:      image.Pt(rng.Intn(width), height-1),
+		rng:       rng,
+		sets:      make([]int, width),
+		openNorth: make([]bool, width),
+	}
+}
+
+func (self *ellerRows) Width() int            { return self.width }
+func (self *ellerRows) Height() int           { return self.height }
+func (self *ellerRows) Entrance() image.Point { return self.entrance }
+func (self *ellerRows) Exit() image.Point     { return self.exit }
+
+func (self *ellerRows) NextRow() ([]board.Field, os.Error) {
+	if self.y >= self.height {
+		return nil, os.EOF
+	}
+	y := self.y
+	row := self.next()
+	if y == self.entrance.Y {
+		row[self.entrance.X].AddDirection(board.N)
+	}
+	if y == self.exit.Y {
+		row[self.exit.X].AddDirection(board.S)
+	}
+	return row, nil
+}
+
+func (self *ellerRows) next() []board.Field {
+	last := self.y == self.height-1
+	self.y++
+	row := make([]board.Field, self.width)
+	for x := range row {
+		if self.openNorth[x] {
+			row[x].AddDirection(board.N)
+		}
+	}
+
+	used := make([]bool, self.width+1)
+	for _, set := range self.sets {
+		used[set] = true
+	}
+	free := 1
+	for x, set := range self.sets {
+		if set == 0 {
+			for used[free] {
+				free++
+			}
+			self.sets[x] = free
+			used[free] = true
+		}
+	}
+
+	for x := 0; x+1 < self.width; x++ {
+		if self.sets[x] != self.sets[x+1] && (last || self.rng.Intn(2) == 0) {
+			row[x].AddDirection(board.E)
+			row[x+1].AddDirection(board.W)
+			merged, into := self.sets[x+1], self.sets[x]
+			for i, set := range self.sets {
+				if set == merged {
+					self.sets[i] = into
+				}
+			}
+		}
+	}
+	if last {
+		return row
+	}
+
+	members := make([][]int, self.width+1)
+	for x, set := range self.sets {
+		members[set] = append(members[set], x)
+	}
+	nextSets := make([]int, self.width)
+	for x := range self.openNorth {
+		self.openNorth[x] = false
+	}
+	for _, set := range self.sets {
+		fields := members[set]
+		if fields == nil {
+			continue
+		}
+		members[set] = nil
+		openedSouth := false
+		for _, x := range fields {
+			if self.rng.Intn(2) == 0 {
+				row[x].AddDirection(board.S)
+				self.openNorth[x] = true
+				nextSets[x] = set
+				openedSouth = true
+			}
+		}
+		if !openedSouth {
+			x := fields[self.rng.Intn(len(fields))]
+			row[x].AddDirection(board.S)
+			self.openNorth[x] = true
+			nextSets[x] = set
+		}
+	}
+	self.sets = nextSets
+	return row
+}
+
+func eller(width, height int, rng *rand.Rand, observer Observer) board.Board {
+	b := board.New(width, height)
+	rows := NewEllerRows(width, height, rng)
+	for y := 0; y < height; y++ {
+		row, _ := rows.NextRow()
+		for x, field := range row {
+			*b.At(x, y) = field
+			// Passages to the east and south lead to fields not copied
+			// yet, so they are reported from the other side.
+			if y > 0 && field.Direction()&board.N != 0 {
+				observer.Carved(image.Pt(x, y), board.N)
+			}
+			if field.Direction()&board.W != 0 {
+				observer.Carved(image.Pt(x, y), board.W)
+			}
+		}
+	}
+	*b.Entrance() = rows.Entrance()
+	*b.Exit() = rows.Exit()
+	return b
+}
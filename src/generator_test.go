@@ -1,40 +1,103 @@
 package generator
 
 import (
-	"container/heap"
-	"image"
+	"os"
 	"rand"
 	"testing"
 	"testutil"
 )
 
-func TestFieldHeap(t *testing.T) {
-	numbers := []int{4, 9, 1, 7, 3, 5, 2, 7}
-	expected := []int{1, 2, 3, 4, 5, 7, 7, 9}
-	h := new(fieldHeap)
-	heap.Init(h)
-	for _, n := range numbers {
-		heap.Push(h, fieldHeapElement{image.Pt(n*2, n*3), n})
-	}
-	for i, n := range expected {
-		actual := heap.Pop(h).(fieldHeapElement)
-		expectedCoords := image.Pt(n*2, n*3)
-		if !actual.Coords.Eq(expectedCoords) {
-			t.Errorf("Coords of element %d are %v, expected %v",
-				i, actual.Coords, expectedCoords)
+func TestBasicStrategies(t *testing.T) {
+	testCases := map[string][]int{
+		"newest": {0, 1, 4},
+		"oldest": {0, 0, 0},
+		"middle": {0, 1, 2},
+	}
+	sizes := []int{1, 2, 5}
+	for name, expected := range testCases {
+		strategy, error := ParseStrategy(name)
+		if error != nil {
+			t.Errorf("Unable to parse strategy %s: %v", name, error)
+			continue
+		}
+		for i, n := range sizes {
+			if selected := strategy.Select(n, nil); selected != expected[i] {
+				t.Errorf("Strategy %s selected %d out of %d, expected %d",
+					name, selected, n, expected[i])
+			}
+		}
+	}
+}
+
+func TestMixedStrategy(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	strategy, error := ParseStrategy("newest:3,oldest:1")
+	if error != nil {
+		t.Fatalf("Unable to parse strategy: %v", error)
+	}
+	const n, trials = 10, 4000
+	newest := 0
+	for i := 0; i < trials; i++ {
+		switch strategy.Select(n, rng) {
+		case n - 1:
+			newest++
+		case 0:
+		default:
+			t.Fatalf("Mixed strategy selected neither the newest nor the oldest field")
+		}
+	}
+	if newest < trials*70/100 || newest > trials*80/100 {
+		t.Errorf("Newest field selected %d times out of %d, expected about 75%%",
+			newest, trials)
+	}
+}
+
+func TestParsingInvalidStrategies(t *testing.T) {
+	for _, spec := range []string{"", "latest", "newest:", "newest:0", "random:x", ",",
+		"newest:0,oldest:0", "newest:-1,oldest:1", "newest:9223372036854775807,oldest:1"} {
+		if _, error := ParseStrategy(spec); error == nil {
+			t.Errorf("Parsing strategy %q succeeded", spec)
+		}
+	}
+}
+
+func TestMixingInvalidStrategies(t *testing.T) {
+	both := []Strategy{Newest, Oldest}
+	for _, test := range []struct {
+		strategies []Strategy
+		weights    []int
+	}{
+		{nil, nil},
+		{both, []int{0, 0}},
+		{both, []int{1, -1}},
+		{both, []int{1}},
+	} {
+		if _, error := Mix(test.strategies, test.weights); error == nil {
+			t.Errorf("Mixing %d strategies with weights %v succeeded",
+				len(test.strategies), test.weights)
+		}
+	}
+}
+
+func TestGrowingTreeStrategies(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for _, spec := range []string{"newest", "oldest", "middle", "random", "newest:75,random:25"} {
+		strategy, error := ParseStrategy(spec)
+		if error != nil {
+			t.Fatalf("Unable to parse strategy %s: %v", spec, error)
 		}
-		if actual.Weight != n {
-			t.Errorf("Weight of element %d is %d, expected %d",
-				i, actual.Weight, n)
+		for _, size := range algorithmTestSizes {
+			checkPerfectMaze(t, "growing tree with strategy "+spec,
+				GrowingTree{strategy}.Generate(size.X, size.Y, rng), size)
 		}
 	}
 }
 
 func TestGenerating(t *testing.T) {
-	rand.Seed(0)
+	rng := rand.New(rand.NewSource(0))
 	dump := false
 	width, height := 10, 5
-	board := Generate(width, height)
+	board := Generate(width, height, rng)
 	if board.Width() != width {
 		t.Errorf("Board width is %d, expected %d", board.Width(), width)
 	}
@@ -80,3 +143,24 @@ func trueMatrix(width, height int) [][]bool {
 	}
 	return matrix
 }
+
+func TestStreamingEllerRows(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	const width, height = 6, 4
+	rows := NewEllerRows(width, height, rng)
+	for y := 0; y < height; y++ {
+		row, error := rows.NextRow()
+		if error != nil {
+			t.Fatalf("Unexpected error in row %d: %v", y, error)
+		}
+		if len(row) != width {
+			t.Errorf("Row %d has %d fields, expected %d", y, len(row), width)
+		}
+	}
+	if _, error := rows.NextRow(); error != os.EOF {
+		t.Errorf("Error after the last row is %v, expected EOF", error)
+	}
+	if NewEllerRows(0, 5, rng) != nil {
+		t.Errorf("Rows of width 0 generated")
+	}
+}
@@ -0,0 +1,150 @@
+package generator
+
+import (
+	"board"
+	"image"
+	"rand"
+)
+
+func backtracker(width, height int, rng *rand.Rand, observer Observer) board.Board {
+	b := board.New(width, height)
+	rect := boardRect(b)
+	visited := newMatrix(width, height)
+	unvisited := func(q image.Point) bool { return !visited[q.Y][q.X] }
+	start := randomPoint(rng, rect)
+	visited[start.Y][start.X] = true
+	stack := []image.Point{start}
+
+	for len(stack) > 0 {
+		observer.Frontier(stack)
+		p := stack[len(stack)-1]
+		possibleDirections := filterDirections(p, rect, unvisited)
+		if len(possibleDirections) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		dir := randomDirection(rng, possibleDirections)
+		carve(b, p, dir, observer)
+		next := neighbour(p, dir)
+		visited[next.Y][next.X] = true
+		stack = append(stack, next)
+	}
+
+	openEntranceAndExit(b, rng)
+	return b
+}
+
+func huntAndKill(width, height int, rng *rand.Rand, observer Observer) board.Board {
+	b := board.New(width, height)
+	rect := boardRect(b)
+	visited := newMatrix(width, height)
+	unvisited := func(q image.Point) bool { return !visited[q.Y][q.X] }
+	isVisited := func(q image.Point) bool { return visited[q.Y][q.X] }
+	p := randomPoint(rng, rect)
+	visited[p.Y][p.X] = true
+	huntRow := 0
+	current := []image.Point{p}
+
+	for {
+		current[0] = p
+		observer.Frontier(current)
+		possibleDirections := filterDirections(p, rect, unvisited)
+		if len(possibleDirections) > 0 {
+			dir := randomDirection(rng, possibleDirections)
+			carve(b, p, dir, observer)
+			p = neighbour(p, dir)
+			visited[p.Y][p.X] = true
+			continue
+		}
+
+		// Rows before huntRow are fully visited. Later rows may have
+		// unvisited fields which only get a visited neighbour once the
+		// walk reaches them, so they are scanned again on every hunt.
+		found := false
+	hunt:
+		for y := huntRow; y < height; y++ {
+			complete := true
+			for x := 0; x < width; x++ {
+				if visited[y][x] {
+					continue
+				}
+				complete = false
+				q := image.Pt(x, y)
+				possibleDirections = filterDirections(q, rect, isVisited)
+				if len(possibleDirections) > 0 {
+					carve(b, q, randomDirection(rng, possibleDirections), observer)
+					visited[q.Y][q.X] = true
+					p = q
+					found = true
+					break hunt
+				}
+			}
+			if complete && y == huntRow {
+				huntRow++
+			}
+		}
+		if !found {
+			break
+		}
+	}
+
+	openEntranceAndExit(b, rng)
+	return b
+}
+
+func aldousBroder(width, height int, rng *rand.Rand, observer Observer) board.Board {
+	b := board.New(width, height)
+	rect := boardRect(b)
+	visited := newMatrix(width, height)
+	p := randomPoint(rng, rect)
+	visited[p.Y][p.X] = true
+	current := []image.Point{p}
+
+	for remaining := width*height - 1; remaining > 0; {
+		current[0] = p
+		observer.Frontier(current)
+		dir := randomDirection(rng, filterDirections(p, rect, anyPoint))
+		next := neighbour(p, dir)
+		if !visited[next.Y][next.X] {
+			carve(b, p, dir, observer)
+			visited[next.Y][next.X] = true
+			remaining--
+		}
+		p = next
+	}
+
+	openEntranceAndExit(b, rng)
+	return b
+}
+
+func wilson(width, height int, rng *rand.Rand, observer Observer) board.Board {
+	b := board.New(width, height)
+	rect := boardRect(b)
+	visited := newMatrix(width, height)
+	walkDirections := make([][]board.Direction, height)
+	for y := range walkDirections {
+		walkDirections[y] = make([]board.Direction, width)
+	}
+	root := randomPoint(rng, rect)
+	visited[root.Y][root.X] = true
+
+	for _, i := range rng.Perm(width * height) {
+		start := image.Pt(i%width, i/width)
+		// Loop-erased random walk: remembering only the last direction taken
+		// out of each field erases any loops the walk makes.
+		for p := start; !visited[p.Y][p.X]; {
+			dir := randomDirection(rng, filterDirections(p, rect, anyPoint))
+			walkDirections[p.Y][p.X] = dir
+			p = neighbour(p, dir)
+		}
+		for p := start; !visited[p.Y][p.X]; {
+			dir := walkDirections[p.Y][p.X]
+			carve(b, p, dir, observer)
+			visited[p.Y][p.X] = true
+			p = neighbour(p, dir)
+		}
+	}
+
+	openEntranceAndExit(b, rng)
+	return b
+}
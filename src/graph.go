@@ -0,0 +1,121 @@
+package graph
+
+import (
+	"board"
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+	"os"
+)
+
+// Edge is a passage between two neighbouring fields, leading from a field to
+// its eastern or southern neighbour.
+type Edge struct {
+	From, To image.Point
+}
+
+// Edges returns the passages of the board row by row, each passage once.
+// Openings of the entrance and exit leading out of the board are left out.
+func Edges(b board.Board) []Edge {
+	edges := make([]Edge, 0)
+	for y := 0; y < b.Height(); y++ {
+		for x := 0; x < b.Width(); x++ {
+			dir := b.At(x, y).Direction()
+			if dir&board.E != 0 && x+1 < b.Width() {
+				edges = append(edges, Edge{image.Pt(x, y), image.Pt(x+1, y)})
+			}
+			if dir&board.S != 0 && y+1 < b.Height() {
+				edges = append(edges, Edge{image.Pt(x, y), image.Pt(x, y+1)})
+			}
+		}
+	}
+	return edges
+}
+
+// highlight tells which fields and edges lie on a path. Edges are identified
+// by the index of their first field and their direction.
+type highlight struct {
+	width  int
+	fields map[int]bool
+	edges  map[int]bool
+}
+
+func newHighlight(b board.Board, path board.Path) *highlight {
+	self := &highlight{
+		width:  b.Width(),
+		fields: make(map[int]bool),
+		edges:  make(map[int]bool),
+	}
+	for i, p := range path {
+		self.fields[self.index(p)] = true
+		if i > 0 {
+			from, to := path[i-1], p
+			if to.X < from.X || to.Y < from.Y {
+				from, to = to, from
+			}
+			self.edges[self.edgeKey(Edge{from, to})] = true
+		}
+	}
+	return self
+}
+
+func (self *highlight) index(p image.Point) int {
+	return p.Y*self.width + p.X
+}
+
+func (self *highlight) edgeKey(edge Edge) int {
+	key := 2 * self.index(edge.From)
+	if edge.To.Y > edge.From.Y {
+		key++
+	}
+	return key
+}
+
+func (self *highlight) field(p image.Point) bool {
+	return self.fields[self.index(p)]
+}
+
+func (self *highlight) edge(edge Edge) bool {
+	return self.edges[self.edgeKey(edge)]
+}
+
+func nodeName(p image.Point) string {
+	return fmt.Sprintf("f%d_%d", p.X, p.Y)
+}
+
+// WriteDOT writes the board as an undirected Graphviz graph with a node per
+// field, pinned to the position of the field, so that neato or fdp lay the
+// graph out like the maze. The entrance and exit, and the fields and passages
+// of the path, which may be nil, are highlighted.
+func WriteDOT(w io.Writer, b board.Board, path board.Path) os.Error {
+	out := bufio.NewWriter(w)
+	onPath := newHighlight(b, path)
+	entrance, exit := *b.Entrance(), *b.Exit()
+	fmt.Fprintf(out, "graph maze {\n")
+	fmt.Fprintf(out, "\tnode [shape=point, width=0.1];\n")
+	for y := 0; y < b.Height(); y++ {
+		for x := 0; x < b.Width(); x++ {
+			p := image.Pt(x, y)
+			attributes := fmt.Sprintf("pos=\"%d,%d!\"", x, -y)
+			switch {
+			case p.Eq(entrance):
+				attributes += ", color=green, width=0.2"
+			case p.Eq(exit):
+				attributes += ", color=red, width=0.2"
+			case onPath.field(p):
+				attributes += ", color=blue"
+			}
+			fmt.Fprintf(out, "\t%s [%s];\n", nodeName(p), attributes)
+		}
+	}
+	for _, edge := range Edges(b) {
+		fmt.Fprintf(out, "\t%s -- %s", nodeName(edge.From), nodeName(edge.To))
+		if onPath.edge(edge) {
+			fmt.Fprintf(out, " [color=blue, penwidth=3]")
+		}
+		fmt.Fprintf(out, ";\n")
+	}
+	fmt.Fprintf(out, "}\n")
+	return out.Flush()
+}
@@ -0,0 +1,23 @@
+package graph
+
+import (
+	"board"
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+)
+
+// WriteCSV writes the passages of the board as a list of edges, one per line,
+// after a header naming the columns: the coordinates of both fields and
+// whether the edge lies on the path, which may be nil.
+func WriteCSV(w io.Writer, b board.Board, path board.Path) os.Error {
+	out := bufio.NewWriter(w)
+	onPath := newHighlight(b, path)
+	fmt.Fprintf(out, "from_x,from_y,to_x,to_y,path\n")
+	for _, edge := range Edges(b) {
+		fmt.Fprintf(out, "%d,%d,%d,%d,%v\n", edge.From.X, edge.From.Y,
+			edge.To.X, edge.To.Y, onPath.edge(edge))
+	}
+	return out.Flush()
+}
@@ -0,0 +1,55 @@
+package graph
+
+import (
+	"board"
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+	"os"
+)
+
+// WriteGraphML writes the board as an undirected GraphML graph. Nodes carry
+// the coordinates of their fields and their role: entrance, exit, path or
+// field. Edges tell whether they lie on the path, which may be nil.
+func WriteGraphML(w io.Writer, b board.Board, path board.Path) os.Error {
+	out := bufio.NewWriter(w)
+	onPath := newHighlight(b, path)
+	entrance, exit := *b.Entrance(), *b.Exit()
+	fmt.Fprintf(out, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(out, "<graphml xmlns=\"http://graphml.graphdrawing.org/xmlns\">\n")
+	fmt.Fprintf(out, "<key id=\"x\" for=\"node\" attr.name=\"x\" attr.type=\"int\"/>\n")
+	fmt.Fprintf(out, "<key id=\"y\" for=\"node\" attr.name=\"y\" attr.type=\"int\"/>\n")
+	fmt.Fprintf(out, "<key id=\"role\" for=\"node\" attr.name=\"role\" attr.type=\"string\"/>\n")
+	fmt.Fprintf(out, "<key id=\"path\" for=\"edge\" attr.name=\"path\" attr.type=\"boolean\">"+
+		"<default>false</default></key>\n")
+	fmt.Fprintf(out, "<graph id=\"maze\" edgedefault=\"undirected\">\n")
+	for y := 0; y < b.Height(); y++ {
+		for x := 0; x < b.Width(); x++ {
+			p := image.Pt(x, y)
+			role := "field"
+			switch {
+			case p.Eq(entrance):
+				role = "entrance"
+			case p.Eq(exit):
+				role = "exit"
+			case onPath.field(p):
+				role = "path"
+			}
+			fmt.Fprintf(out, "<node id=\"%s\"><data key=\"x\">%d</data>"+
+				"<data key=\"y\">%d</data><data key=\"role\">%s</data></node>\n",
+				nodeName(p), x, y, role)
+		}
+	}
+	for _, edge := range Edges(b) {
+		fmt.Fprintf(out, "<edge source=\"%s\" target=\"%s\"",
+			nodeName(edge.From), nodeName(edge.To))
+		if onPath.edge(edge) {
+			fmt.Fprintf(out, "><data key=\"path\">true</data></edge>\n")
+		} else {
+			fmt.Fprintf(out, "/>\n")
+		}
+	}
+	fmt.Fprintf(out, "</graph>\n</graphml>\n")
+	return out.Flush()
+}
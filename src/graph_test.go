@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"board"
+	"bytes"
+	"image"
+	"strings"
+	"testing"
+)
+
+// +--+--+
+// |*    |
+// +  +--+
+// |    x|
+// +--+--+
+func smallBoard() board.Board {
+	b := board.New(2, 2)
+	b.At(0, 0).AddDirection(board.N | board.E | board.S)
+	b.At(1, 0).AddDirection(board.W)
+	b.At(0, 1).AddDirection(board.N | board.E)
+	b.At(1, 1).AddDirection(board.W | board.S)
+	return b
+}
+
+var smallPath = board.Path{image.Pt(0, 0), image.Pt(0, 1), image.Pt(1, 1)}
+
+func TestEdges(t *testing.T) {
+	edges := Edges(smallBoard())
+	expected := []Edge{
+		{image.Pt(0, 0), image.Pt(1, 0)},
+		{image.Pt(0, 0), image.Pt(0, 1)},
+		{image.Pt(0, 1), image.Pt(1, 1)},
+	}
+	if len(edges) != len(expected) {
+		t.Fatalf("Edges are %v, expected %v", edges, expected)
+	}
+	for i, edge := range edges {
+		if !edge.From.Eq(expected[i].From) || !edge.To.Eq(expected[i].To) {
+			t.Errorf("Edge %d is %v, expected %v", i, edge, expected[i])
+		}
+	}
+}
+
+func TestWritingDOT(t *testing.T) {
+	var buf bytes.Buffer
+	if error := WriteDOT(&buf, smallBoard(), smallPath); error != nil {
+		t.Fatalf("Unable to write DOT: %v", error)
+	}
+	dot := buf.String()
+	for _, expected := range []string{
+		"graph maze {\n",
+		"\tf0_0 [pos=\"0,0!\", color=green, width=0.2];\n",
+		"\tf1_0 [pos=\"1,0!\"];\n",
+		"\tf1_1 [pos=\"1,-1!\", color=red, width=0.2];\n",
+		"\tf0_0 -- f1_0;\n",
+		"\tf0_0 -- f0_1 [color=blue, penwidth=3];\n",
+		"\tf0_1 -- f1_1 [color=blue, penwidth=3];\n",
+	} {
+		if !strings.Contains(dot, expected) {
+			t.Errorf("DOT output doesn't contain %q:\n%s", expected, dot)
+		}
+	}
+	if strings.Count(dot, " -- ") != 3 {
+		t.Errorf("DOT output doesn't have 3 edges:\n%s", dot)
+	}
+}
+
+func TestWritingGraphML(t *testing.T) {
+	var buf bytes.Buffer
+	if error := WriteGraphML(&buf, smallBoard(), smallPath); error != nil {
+		t.Fatalf("Unable to write GraphML: %v", error)
+	}
+	graphML := buf.String()
+	for _, expected := range []string{
+		"<node id=\"f0_1\"><data key=\"x\">0</data><data key=\"y\">1</data>" +
+			"<data key=\"role\">path</data></node>\n",
+		"<node id=\"f1_1\"><data key=\"x\">1</data><data key=\"y\">1</data>" +
+			"<data key=\"role\">exit</data></node>\n",
+		"<edge source=\"f0_0\" target=\"f1_0\"/>\n",
+		"<edge source=\"f0_1\" target=\"f1_1\"><data key=\"path\">true</data></edge>\n",
+		"</graph>\n</graphml>\n",
+	} {
+		if !strings.Contains(graphML, expected) {
+			t.Errorf("GraphML output doesn't contain %q:\n%s", expected, graphML)
+		}
+	}
+}
+
+func TestWritingCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if error := WriteCSV(&buf, smallBoard(), smallPath); error != nil {
+		t.Fatalf("Unable to write CSV: %v", error)
+	}
+	expected := "" +
+		"from_x,from_y,to_x,to_y,path\n" +
+		"0,0,1,0,false\n" +
+		"0,0,0,1,true\n" +
+		"0,1,1,1,true\n"
+	if buf.String() != expected {
+		t.Errorf("CSV output is\n%s\nexpected\n%s", buf.String(), expected)
+	}
+}
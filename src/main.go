@@ -2,22 +2,93 @@ package main
 
 import (
 	"board"
+	"bufio"
+	"flag"
 	"fmt"
 	"generator"
+	"graph"
+	"image"
 	"image/png"
+	"io"
 	"os"
 	"painter"
+	"path"
 	"rand"
 	"strconv"
+	"strings"
 	"time"
 )
 
+var algorithmName = flag.String("algorithm", "growing-tree",
+	"maze generation algorithm")
+var strategySpec = flag.String("strategy", "",
+	"cell selection of the growing-tree algorithm, e.g. newest:75,random:25")
+var braid = flag.Float64("braid", 0,
+	"fraction of dead ends to remove by adding loops, from 0 to 1")
+var placementSpec = flag.String("placement", "",
+	"placement of the entrance and exit, e.g. diameter or fixed:0,0:9,9")
+var seed = flag.Int64("seed", 0,
+	"seed of the random number generator; picked at random if 0")
+var stream = flag.Bool("stream", false,
+	"generate the maze row by row with Eller's algorithm, in constant memory")
+var count = flag.Int("count", 1, "number of mazes in a PDF book")
+var page = flag.String("page", "a4", "page size of a PDF book, a4 or letter")
+var margin = flag.Float64("margin", 15, "page margin of a PDF book in millimetres")
+var perPage = flag.Int("per-page", 1, "number of mazes on each page of a PDF book")
+var answers = flag.Bool("answers", false,
+	"add pages with the solutions to the end of a PDF book")
+var title = flag.String("title", "Maze", "title of the mazes in a PDF book")
+var animate = flag.String("animate", "generation",
+	"what a GIF output animates: generation or solving; solving can also be written "+
+		"as a PNG sequence, such as frame%04d.png")
+var every = flag.Int("every", 1,
+	"number of steps between the frames of an animation")
+var solverName = flag.String("solver", "dfs", "solver to animate")
+var solve = flag.Bool("solve", false,
+	"draw the solution and mark the entrance and exit")
+var themeFile = flag.String("theme", "",
+	"JSON or TOML file with the rendering style")
+var styleSpec = flag.String("style", "",
+	"rendering style options overriding the theme, e.g. cell-size=20,caps=round")
+var pathColorSpec = flag.String("path-color", "",
+	"colour of the solution path, in hexadecimal")
+var pathWidth = flag.Int("path-width", 0, "width of the solution path")
+var heatmap = flag.String("heatmap", "",
+	"fill the fields by their distance from the entrance using the palette")
+var heatmapFrom = flag.String("heatmap-from", "",
+	"field the heatmap distances are measured from, e.g. 3,4")
+var format = flag.String("format", "pretty",
+	"format of a maze printed as text, or written to a .txt output")
+var tileSize = flag.Int("tile-size", 0,
+	"write the output as a directory of z/x/y.png tiles of this size for web map viewers")
+var wallHeight = flag.Float64("wall-height", painter.DefaultMeshStyle.WallHeight,
+	"height of the walls of an STL or OBJ output in millimetres, above the base plate")
+var baseDepth = flag.Float64("base-depth", painter.DefaultMeshStyle.BaseDepth,
+	"depth of the base plate of an STL or OBJ output in millimetres")
+
+// style is the rendering style, loaded from the theme and the flags.
+var style painter.Style = painter.DefaultStyle
+
 func printUsage() {
-	fmt.Fprintf(os.Stderr, "Usage: %s width height [output]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] width height [output.png|output.svg|output.pdf|output.gif|output.txt|output.dot|output.graphml|output.csv|output.stl|output.obj]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s save [flags] width height [maze.json|maze.bin]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s load [flags] maze.json|maze.bin [output]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s code [flags] code [output]\n", os.Args[0])
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "Algorithms: %s\n",
+		strings.Join(generator.Names(), ", "))
+	fmt.Fprintf(os.Stderr, "Placements: %s\n",
+		strings.Join(generator.PlacementNames(), ", "))
+	fmt.Fprintf(os.Stderr, "Solvers: %s\n",
+		strings.Join(board.SolverNames(), ", "))
+	fmt.Fprintf(os.Stderr, "Palettes: %s\n",
+		strings.Join(painter.PaletteNames(), ", "))
+	fmt.Fprintf(os.Stderr, "Text formats: %s\n",
+		strings.Join(board.TextFormatNames(), ", "))
 }
 
 func getIntArg(index int, name string) (val int, error os.Error) {
-	val, error = strconv.Atoi(os.Args[index])
+	val, error = strconv.Atoi(flag.Arg(index))
 	if error != nil {
 		fmt.Fprintf(os.Stderr, "Invalid %s: %v\n", name, error)
 		printUsage()
@@ -25,19 +96,127 @@ func getIntArg(index int, name string) (val int, error os.Error) {
 	return
 }
 
-func drawToFile(b board.Board, fileName string) os.Error {
-	//solution, error := b.Walk(true)
-	//if error != nil {
-		//return error
-	//}
-	img := painter.Paint(b, nil, 10, 2)
+// printText writes the board in the format given by -format, with the
+// solution if -solve is set.
+func printText(w io.Writer, b board.Board) os.Error {
+	textFormat, error := board.LookupTextFormat(*format)
+	if error != nil {
+		return error
+	}
+	var solution board.Path
+	if *solve {
+		if solution, error = b.Solve(); error != nil {
+			return error
+		}
+	}
+	return textFormat(w, b, solution)
+}
+
+// graphExporters write the board as a graph, chosen by the extension of the
+// output file.
+var graphExporters = map[string]func(io.Writer, board.Board, board.Path) os.Error{
+	".dot":     graph.WriteDOT,
+	".graphml": graph.WriteGraphML,
+	".csv":     graph.WriteCSV,
+}
+
+func exportGraph(b board.Board, fileName string,
+	export func(io.Writer, board.Board, board.Path) os.Error) os.Error {
+	var solution board.Path
+	if *solve {
+		var error os.Error
+		if solution, error = b.Solve(); error != nil {
+			return error
+		}
+	}
 	file, error := os.Create(fileName)
+	if error != nil {
+		return error
+	}
+	defer file.Close()
+	return export(file, b, solution)
+}
+
+// exportMesh writes the board as a solid for 3D printing, one pixel of the
+// rendering style to a millimetre.
+func exportMesh(b board.Board, fileName string) os.Error {
+	meshStyle := painter.MeshStyle{
+		CellSize:      float64(style.CellSize),
+		WallThickness: float64(style.WallThickness),
+		WallHeight:    *wallHeight,
+		BaseDepth:     *baseDepth,
+	}
+	mesh, error := meshStyle.Mesh(b)
+	if error != nil {
+		return error
+	}
+	file, error := os.Create(fileName)
+	if error != nil {
+		return error
+	}
 	defer file.Close()
+	if strings.ToLower(path.Ext(fileName)) == ".obj" {
+		return mesh.WriteOBJ(file)
+	}
+	return mesh.WriteSTL(file)
+}
+
+func drawToFile(b board.Board, fileName string) os.Error {
+	if strings.ToLower(path.Ext(fileName)) == ".txt" {
+		file, error := os.Create(fileName)
+		if error != nil {
+			return error
+		}
+		defer file.Close()
+		return printText(file, b)
+	}
+	if export, ok := graphExporters[strings.ToLower(path.Ext(fileName))]; ok {
+		return exportGraph(b, fileName, export)
+	}
+	switch strings.ToLower(path.Ext(fileName)) {
+	case ".stl", ".obj":
+		return exportMesh(b, fileName)
+	}
+	var solution board.Path
+	if *solve {
+		var error os.Error
+		if solution, error = b.Solve(); error != nil {
+			fmt.Fprintln(os.Stderr, error)
+			return error
+		}
+	}
+	if *tileSize > 0 {
+		if *heatmap != "" {
+			return os.NewError("Heatmaps can't be written as tiles")
+		}
+		return style.WriteTiles(fileName, b, solution, *tileSize)
+	}
+	var img image.Image
+	if *heatmap != "" {
+		var error os.Error
+		if img, error = paintHeatmap(b, solution); error != nil {
+			fmt.Fprintln(os.Stderr, error)
+			return error
+		}
+	}
+	file, error := os.Create(fileName)
 	if error != nil {
 		fmt.Fprintln(os.Stderr, error)
 		return error
 	}
-	error = png.Encode(file, img)
+	defer file.Close()
+	switch {
+	case strings.ToLower(path.Ext(fileName)) == ".svg":
+		if img != nil {
+			error = os.NewError("Heatmaps can only be painted as PNG")
+		} else {
+			error = style.PaintSVG(file, b, solution)
+		}
+	case img != nil:
+		error = png.Encode(file, img)
+	default:
+		error = style.WritePNG(file, b, solution)
+	}
 	if error != nil {
 		fmt.Fprintln(os.Stderr, error)
 		return error
@@ -45,28 +224,392 @@ func drawToFile(b board.Board, fileName string) os.Error {
 	return nil
 }
 
+// paintHeatmap paints the distances from the field given by -heatmap-from,
+// or from the entrance.
+func paintHeatmap(b board.Board, solution board.Path) (image.Image, os.Error) {
+	palette, error := painter.LookupPalette(*heatmap)
+	if error != nil {
+		return nil, error
+	}
+	source := *b.Entrance()
+	if *heatmapFrom != "" {
+		if _, error = fmt.Sscanf(*heatmapFrom, "%d,%d", &source.X, &source.Y); error != nil {
+			return nil, os.NewError("Invalid field " + *heatmapFrom)
+		}
+	}
+	distanceMap, error := b.Distances(source, nil)
+	if error != nil {
+		return nil, error
+	}
+	return style.PaintHeatmap(b, distanceMap.Distances, palette, solution), nil
+}
+
+func streamToFile(width, height int, rng *rand.Rand, fileName string) os.Error {
+	rows := generator.NewEllerRows(width, height, rng)
+	if rows == nil {
+		return fmt.Errorf("Invalid board size: %dx%d", width, height)
+	}
+	if fileName == "" {
+		return board.WritePrettyRows(os.Stdout, rows)
+	}
+	if strings.ToLower(path.Ext(fileName)) != ".png" {
+		return os.NewError("Streaming only supports PNG output")
+	}
+	file, error := os.Create(fileName)
+	if error != nil {
+		return error
+	}
+	defer file.Close()
+	return style.PaintRows(file, rows)
+}
+
+// generateBoard generates a board from the seed, so that the same seed always
+// yields the same board.
+func generateBoard(algorithm generator.Algorithm, width, height int,
+	seed int64, observer generator.Observer) (board.Board, os.Error) {
+	rng := rand.New(rand.NewSource(seed))
+	var b board.Board
+	if observer != nil {
+		observable, ok := algorithm.(generator.ObservableAlgorithm)
+		if !ok {
+			return nil, os.NewError("The algorithm can't be animated")
+		}
+		b = observable.GenerateObserved(width, height, rng, observer)
+	} else {
+		b = algorithm.Generate(width, height, rng)
+	}
+	if b == nil {
+		return nil, fmt.Errorf("Invalid board size: %dx%d", width, height)
+	}
+	if *braid > 0 {
+		generator.Braid(b, *braid, rng)
+	}
+	if *placementSpec != "" {
+		placement, error := generator.ParsePlacement(*placementSpec)
+		if error != nil {
+			return nil, error
+		}
+		if error = placement.Place(b, rng); error != nil {
+			return nil, error
+		}
+	}
+	return b, nil
+}
+
+// writeBook generates count mazes from consecutive seeds, so that each of them
+// can be reproduced on its own with the seed from its caption.
+func writeBook(algorithm generator.Algorithm, width, height int, fileName string) os.Error {
+	pageSize, error := painter.LookupPageSize(*page)
+	if error != nil {
+		return error
+	}
+	if *count < 1 {
+		return fmt.Errorf("Invalid number of mazes in a book: %d", *count)
+	}
+	puzzles := make([]painter.Puzzle, *count)
+	for i := range puzzles {
+		puzzleSeed := *seed + int64(i)
+		b, error := generateBoard(algorithm, width, height, puzzleSeed, nil)
+		if error != nil {
+			return error
+		}
+		puzzles[i] = painter.Puzzle{
+			Board: b,
+			Title: fmt.Sprintf("%s %d", *title, i+1),
+			Seed:  puzzleSeed,
+		}
+	}
+	file, error := os.Create(fileName)
+	if error != nil {
+		return error
+	}
+	defer file.Close()
+	book := painter.Book{
+		Page:    pageSize,
+		Margin:  painter.Millimetres(*margin),
+		PerPage: *perPage,
+		Answers: *answers,
+		Style:   &style,
+	}
+	return book.Write(file, puzzles)
+}
+
+func animateToFile(algorithm generator.Algorithm, width, height int, fileName string) os.Error {
+	file, error := os.Create(fileName)
+	if error != nil {
+		return error
+	}
+	defer file.Close()
+	animation, error := style.NewGenerationAnimation(file, width, height, *every)
+	if error != nil {
+		return error
+	}
+	b, error := generateBoard(algorithm, width, height, *seed, animation)
+	if error != nil {
+		return error
+	}
+	return animation.Close(b)
+}
+
+// animateSolving animates the solver as a GIF, or as a sequence of PNG files
+// if the file name contains a frame number pattern.
+func animateSolving(algorithm generator.Algorithm, width, height int, fileName string) os.Error {
+	solver, error := board.LookupSolver(*solverName)
+	if error != nil {
+		return error
+	}
+	b, error := generateBoard(algorithm, width, height, *seed, nil)
+	if error != nil {
+		return error
+	}
+	var animation *painter.SolveAnimation
+	if strings.Contains(fileName, "%") {
+		animation, error = style.NewSolvePNGSequence(fileName, b, *every)
+	} else {
+		if strings.ToLower(path.Ext(fileName)) != ".gif" {
+			return os.NewError("Solving can only be animated as GIF or PNG sequence")
+		}
+		var file *os.File
+		if file, error = os.Create(fileName); error != nil {
+			return error
+		}
+		defer file.Close()
+		animation, error = style.NewSolveAnimation(file, b, *every)
+	}
+	if error != nil {
+		return error
+	}
+	_, error = solver.Solve(b, animation)
+	if closeError := animation.Close(); error == nil {
+		error = closeError
+	}
+	return error
+}
+
+func loadStyle() (error os.Error) {
+	if *themeFile != "" {
+		if style, error = painter.LoadStyle(*themeFile); error != nil {
+			return
+		}
+	}
+	if *pathColorSpec != "" {
+		if error = style.Set("path-color", *pathColorSpec); error != nil {
+			return
+		}
+	}
+	if *pathWidth > 0 {
+		style.PathWidth = *pathWidth
+	}
+	return style.Parse(*styleSpec)
+}
+
+// saveBoard writes the board to the file or, if the name is empty, to the
+// standard output, in the binary format if the file name ends with .bin, or
+// as JSON otherwise.
+func saveBoard(b board.Board, fileName string) os.Error {
+	out := os.Stdout
+	if fileName != "" {
+		file, error := os.Create(fileName)
+		if error != nil {
+			return error
+		}
+		defer file.Close()
+		out = file
+	}
+	if strings.ToLower(path.Ext(fileName)) == ".bin" {
+		return board.WriteBinary(out, b)
+	}
+	return board.WriteJSON(out, &board.Record{Board: b, Generator: *algorithmName, Seed: *seed})
+}
+
+// loadBoard reads a board saved as JSON and draws it like a generated one.
+func loadBoard(fileName, output string) os.Error {
+	file, error := os.Open(fileName)
+	if error != nil {
+		return error
+	}
+	defer file.Close()
+	var b board.Board
+	if strings.ToLower(path.Ext(fileName)) == ".bin" {
+		b, error = board.ReadBinary(bufio.NewReader(file))
+	} else {
+		var record *board.Record
+		if record, error = board.ReadJSON(file); error == nil {
+			fmt.Fprintf(os.Stderr, "Generator: %s, seed: %d\n", record.Generator, record.Seed)
+			b = record.Board
+		}
+	}
+	if error != nil {
+		return fmt.Errorf("%s: %v", fileName, error)
+	}
+	if output != "" {
+		return drawToFile(b, output)
+	}
+	return printText(os.Stdout, b)
+}
+
+// generateFromCode generates the maze of the code and draws it like a maze
+// generated from the flags.
+func generateFromCode(text, output string) os.Error {
+	code, error := generator.DecodeCode(text)
+	if error != nil {
+		return error
+	}
+	fmt.Fprintf(os.Stderr, "Algorithm: %s, size: %dx%d, seed: %d\n",
+		code.Algorithm, code.Width, code.Height, code.Seed)
+	b, error := code.Generate()
+	if error != nil {
+		return error
+	}
+	if output != "" {
+		return drawToFile(b, output)
+	}
+	return printText(os.Stdout, b)
+}
+
 func main() {
-	rand.Seed(time.Nanoseconds())
-	if len(os.Args) < 3 || len(os.Args) > 4 {
+	flag.Usage = printUsage
+	command := ""
+	if len(os.Args) > 1 && (os.Args[1] == "save" || os.Args[1] == "load" || os.Args[1] == "code") {
+		command = os.Args[1]
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+	flag.Parse()
+	withoutSize := command == "load" || command == "code"
+	if withoutSize && (flag.NArg() < 1 || flag.NArg() > 2) ||
+		!withoutSize && (flag.NArg() < 2 || flag.NArg() > 3) {
 		printUsage()
 		return
 	}
-	width, error := getIntArg(1, "width")
+	if error := loadStyle(); error != nil {
+		fmt.Fprintln(os.Stderr, error)
+		return
+	}
+	if command == "load" {
+		if error := loadBoard(flag.Arg(0), flag.Arg(1)); error != nil {
+			fmt.Fprintf(os.Stderr, "Error while loading the maze: %v\n", error)
+		}
+		return
+	}
+	if command == "code" {
+		if error := generateFromCode(flag.Arg(0), flag.Arg(1)); error != nil {
+			fmt.Fprintf(os.Stderr, "Error while generating the maze: %v\n", error)
+		}
+		return
+	}
+	algorithm, error := generator.Lookup(*algorithmName)
+	if error != nil {
+		fmt.Fprintln(os.Stderr, error)
+		printUsage()
+		return
+	}
+	if *strategySpec != "" {
+		if *algorithmName != "growing-tree" {
+			fmt.Fprintln(os.Stderr,
+				"Strategy can only be used with the growing-tree algorithm")
+			return
+		}
+		strategy, error := generator.ParseStrategy(*strategySpec)
+		if error != nil {
+			fmt.Fprintln(os.Stderr, error)
+			return
+		}
+		algorithm = generator.GrowingTree{Strategy: strategy}
+	}
+	if *stream {
+		// Streaming always uses Eller's algorithm, so any other one asked
+		// for explicitly would silently yield a different maze.
+		otherAlgorithm := *strategySpec != ""
+		flag.Visit(func(f *flag.Flag) {
+			if f.Name == "algorithm" && *algorithmName != "eller" {
+				otherAlgorithm = true
+			}
+		})
+		if otherAlgorithm {
+			fmt.Fprintln(os.Stderr, "Streaming only supports Eller's algorithm")
+			return
+		}
+	}
+	width, error := getIntArg(0, "width")
 	if error != nil {
 		return
 	}
-	height, error := getIntArg(2, "height")
+	height, error := getIntArg(1, "height")
+	if error != nil {
+		return
+	}
+	if *seed == 0 {
+		*seed = time.Nanoseconds()
+	}
+	fmt.Fprintf(os.Stderr, "Seed: %d\n", *seed)
+	if *strategySpec == "" && !*stream {
+		code := generator.Code{
+			Algorithm: *algorithmName,
+			Width:     width,
+			Height:    height,
+			Seed:      *seed,
+			Braid:     *braid,
+			Placement: *placementSpec,
+		}
+		if text, error := code.Encode(); error == nil {
+			fmt.Fprintf(os.Stderr, "Code: %s\n", text)
+		}
+	}
+	if command == "save" {
+		b, error := generateBoard(algorithm, width, height, *seed, nil)
+		if error == nil {
+			error = saveBoard(b, flag.Arg(2))
+		}
+		if error != nil {
+			fmt.Fprintf(os.Stderr, "Error while saving the maze: %v\n", error)
+		}
+		return
+	}
+	if *stream {
+		rng := rand.New(rand.NewSource(*seed))
+		error = streamToFile(width, height, rng, flag.Arg(2))
+		if error != nil {
+			fmt.Fprintf(os.Stderr, "Error while streaming the maze: %v\n", error)
+		}
+		return
+	}
+	if strings.ToLower(path.Ext(flag.Arg(2))) == ".pdf" {
+		error = writeBook(algorithm, width, height, flag.Arg(2))
+		if error != nil {
+			fmt.Fprintf(os.Stderr, "Error while writing the book: %v\n", error)
+		}
+		return
+	}
+	if *animate == "solving" {
+		error = animateSolving(algorithm, width, height, flag.Arg(2))
+		if error != nil {
+			fmt.Fprintf(os.Stderr, "Error while animating the solver: %v\n", error)
+		}
+		return
+	}
+	if strings.ToLower(path.Ext(flag.Arg(2))) == ".gif" {
+		error = animateToFile(algorithm, width, height, flag.Arg(2))
+		if error != nil {
+			fmt.Fprintf(os.Stderr, "Error while animating the maze: %v\n", error)
+		}
+		return
+	}
+	b, error := generateBoard(algorithm, width, height, *seed, nil)
 	if error != nil {
+		fmt.Fprintln(os.Stderr, error)
 		return
 	}
-	b := generator.Generate(width, height)
-	if len(os.Args) == 4 {
-		error = drawToFile(b, os.Args[3])
+	if flag.NArg() == 3 {
+		error = drawToFile(b, flag.Arg(2))
 		if error != nil {
 			fmt.Fprintf(os.Stderr,
 				"Error while drawing the maze: %v", error)
 		}
 	} else {
-		fmt.Println(b.PrettyString())
+		error = printText(os.Stdout, b)
+		if error != nil {
+			fmt.Fprintf(os.Stderr,
+				"Error while printing the maze: %v\n", error)
+		}
 	}
 }
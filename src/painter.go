@@ -6,6 +6,8 @@ package painter
 import (
 	"board"
 	"image"
+	"io"
+	"os"
 )
 
 var (
@@ -15,6 +17,7 @@ var (
 )
 
 func DrawRect(img *image.RGBA, rect image.Rectangle, color image.RGBAColor) {
+	rect = rect.Intersect(img.Bounds())
 	for y := rect.Min.Y; y < rect.Max.Y; y++ {
 		for x := rect.Min.X; x < rect.Max.X; x++ {
 			img.SetRGBA(x, y, color)
@@ -23,79 +26,212 @@ func DrawRect(img *image.RGBA, rect image.Rectangle, color image.RGBAColor) {
 }
 
 func Paint(b board.Board, visitMatrix [][]bool, cellSize, wallThickness int) image.Image {
-	width := b.Width()*(cellSize) + wallThickness
-	height := b.Height()*(cellSize) + wallThickness
-	img := image.NewRGBA(width, height)
-	bounds := img.Bounds()
+	style := DefaultStyle
+	style.CellSize, style.WallThickness = cellSize, wallThickness
+	return style.Paint(b, visitMatrix, nil)
+}
+
+// Paint renders the board with the fields of the visit matrix, which may be
+// nil, filled with the path colour. If the path is not nil, it is drawn as a
+// line through the centres of its fields.
+func (self Style) Paint(b board.Board, visitMatrix [][]bool, path board.Path) image.Image {
+	return self.paint(b, func(x, y int) (image.RGBAColor, bool) {
+		return self.PathColor, visitMatrix != nil && visitMatrix[y][x]
+	}, false, path)
+}
 
-	DrawRect(img, bounds, boardColor)
+// paint renders the board with the fields for which fill returns true filled
+// with the returned colour. If fillPassages is set, so are the gaps in the
+// walls leading to the northern and western neighbours.
+func (self Style) paint(b board.Board, fill func(x, y int) (image.RGBAColor, bool),
+	fillPassages bool, path board.Path) image.Image {
+	size := self.imageSize(b)
+	return self.paintRegion(b, image.Rect(0, 0, size.X, size.Y), fill, fillPassages, path)
+}
 
-	for y := 0; y < b.Height(); y++ {
-		yBase := y*cellSize + bounds.Min.Y
-		for x := 0; x < b.Width(); x++ {
-			xBase := x*cellSize + bounds.Min.X
-			if visitMatrix != nil && visitMatrix[y][x] {
-				DrawRect(img, image.Rect(
-					xBase+wallThickness,
-					yBase+wallThickness,
-					xBase+cellSize,
-					yBase+cellSize), pathColor)
+// imageSize returns the size of the whole picture of the board.
+func (self Style) imageSize(b board.Board) image.Point {
+	return image.Pt(b.Width()*self.CellSize+self.WallThickness+2*self.Margin,
+		b.Height()*self.CellSize+self.WallThickness+2*self.Margin)
+}
+
+// paintRegion renders the part of the picture of the board within the region,
+// which is moved to the origin of the returned image. Only the fields
+// overlapping the region are drawn, so that small parts of huge boards can be
+// rendered quickly.
+func (self Style) paintRegion(b board.Board, region image.Rectangle,
+	fill func(x, y int) (image.RGBAColor, bool), fillPassages bool,
+	path board.Path) *image.RGBA {
+	cellSize, wallThickness := self.CellSize, self.WallThickness
+	width, height := region.Dx(), region.Dy()
+	img := image.NewRGBA(width, height)
+	size := self.imageSize(b)
+	origin := image.Pt(self.Margin, self.Margin).Sub(region.Min)
+	DrawRect(img, image.Rect(0, 0, size.X, size.Y).Sub(region.Min), self.BoardColor)
+
+	first := region.Min.Sub(image.Pt(self.Margin, self.Margin)).Div(cellSize)
+	last := region.Max.Sub(image.Pt(self.Margin, self.Margin)).Div(cellSize)
+	first.X, first.Y = max(first.X, 0), max(first.Y, 0)
+	last.X, last.Y = min(last.X, b.Width()), min(last.Y, b.Height())
+	for y := first.Y; y <= last.Y; y++ {
+		for x := first.X; x <= last.X; x++ {
+			base := origin.Add(image.Pt(x*cellSize, y*cellSize))
+			if x < b.Width() && y < b.Height() {
+				if color, filled := fill(x, y); filled {
+					DrawRect(img, image.Rect(
+						base.X+wallThickness,
+						base.Y+wallThickness,
+						base.X+cellSize,
+						base.Y+cellSize), color)
+					dir := b.At(x, y).Direction()
+					if fillPassages && x > 0 && dir&board.W != 0 {
+						DrawRect(img, image.Rect(base.X, base.Y+wallThickness,
+							base.X+wallThickness, base.Y+cellSize), color)
+					}
+					if fillPassages && y > 0 && dir&board.N != 0 {
+						DrawRect(img, image.Rect(base.X+wallThickness, base.Y,
+							base.X+cellSize, base.Y+wallThickness), color)
+					}
+				}
 			}
-			DrawRect(img, image.Rect(
-				xBase,
-				yBase,
-				xBase+wallThickness,
-				yBase+wallThickness), wallColor)
-			dir := b.At(x, y).Direction()
-			if dir&board.N == 0 {
+			self.drawPost(img, b, image.Pt(x, y), base)
+			if x < b.Width() && !hasPassage(b, x, y, board.N) {
 				DrawRect(img, image.Rect(
-					xBase+wallThickness,
-					yBase,
-					xBase+cellSize,
-					yBase+wallThickness), wallColor)
+					base.X+wallThickness,
+					base.Y,
+					base.X+cellSize,
+					base.Y+wallThickness), self.WallColor)
 			}
-			if dir&board.W == 0 {
+			if y < b.Height() && !hasPassage(b, x, y, board.W) {
 				DrawRect(img, image.Rect(
-					xBase,
-					yBase+wallThickness,
-					xBase+wallThickness,
-					yBase+cellSize), wallColor)
+					base.X,
+					base.Y+wallThickness,
+					base.X+wallThickness,
+					base.Y+cellSize), self.WallColor)
 			}
 		}
-		var lastHeight int
-		if b.At(b.Width()-1, y).Direction()&board.E == 0 {
-			lastHeight = cellSize
-		} else {
-			lastHeight = wallThickness
+	}
+
+	if path != nil {
+		drawPath(img, origin, path, cellSize, wallThickness, self.PathColor, self.PathWidth)
+		if self.Markers {
+			drawMarkers(img, origin, b, cellSize, wallThickness,
+				self.EntranceColor, self.ExitColor)
+		}
+	}
+	return img
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// drawPost draws the post in the given corner of the fields.
+func (self Style) drawPost(img *image.RGBA, b board.Board, corner, base image.Point) {
+	inPost := self.postShape(b, corner)
+	for y := 0; y < self.WallThickness; y++ {
+		for x := 0; x < self.WallThickness; x++ {
+			if inPost(x, y) {
+				img.SetRGBA(base.X+x, base.Y+y, self.WallColor)
+			}
 		}
-		xBase := b.Width()*cellSize + bounds.Min.X
-		DrawRect(img, image.Rect(
-			xBase,
-			yBase,
-			xBase+wallThickness,
-			yBase+lastHeight), wallColor)
 	}
+}
 
-	yBase := b.Height()*cellSize + bounds.Min.Y
-	for x := 0; x < b.Width(); x++ {
-		var lastWidth int
-		if b.At(x, b.Height()-1).Direction()&board.S == 0 {
-			lastWidth = cellSize
+// postShape tells which pixels of the post in the given corner, relative to
+// its top left pixel, are drawn. The post is a square or, with round caps, a
+// disc the size of the wall thickness. Posts in the middle of a straight wall
+// are always square.
+func (self Style) postShape(b board.Board, corner image.Point) func(x, y int) bool {
+	x, y := corner.X, corner.Y
+	left := x > 0 && !hasPassage(b, x-1, y, board.N)
+	right := x < b.Width() && !hasPassage(b, x, y, board.N)
+	up := y > 0 && !hasPassage(b, x, y-1, board.W)
+	down := y < b.Height() && !hasPassage(b, x, y, board.W)
+	if !self.RoundCaps || (left && right) || (up && down) {
+		return func(x, y int) bool { return true }
+	}
+	radius := float64(self.WallThickness) / 2
+	return func(x, y int) bool {
+		dx, dy := float64(x)+0.5-radius, float64(y)+0.5-radius
+		return dx*dx+dy*dy <= radius*radius
+	}
+}
+
+// PaintRows renders the rows like Paint does, but encodes the picture as PNG
+// on the fly. Each row is painted together with the one above it, so that the
+// walls and posts between them look the same as on the whole board, and only
+// these two rows are kept in memory.
+func (self Style) PaintRows(w io.Writer, rows board.Rows) os.Error {
+	width := rows.Width()*self.CellSize + self.WallThickness + 2*self.Margin
+	height := rows.Height()*self.CellSize + self.WallThickness + 2*self.Margin
+	out, error := newPNGWriter(w, width, height)
+	if error != nil {
+		return error
+	}
+	var previous []board.Field
+	for {
+		row, error := rows.NextRow()
+		if error == os.EOF {
+			break
+		}
+		if error != nil {
+			return error
+		}
+		if previous == nil {
+			error = self.writeWindow(out, [][]board.Field{row}, 0, self.Margin+self.CellSize)
 		} else {
-			lastWidth = wallThickness
+			error = self.writeWindow(out, [][]board.Field{previous, row},
+				self.Margin+self.CellSize, self.Margin+2*self.CellSize)
+		}
+		if error != nil {
+			return error
+		}
+		previous = row
+	}
+	if previous != nil {
+		// The bottom wall of the last row and the margin below it.
+		if error = self.writeWindow(out, [][]board.Field{previous}, self.Margin+self.CellSize,
+			self.Margin+self.CellSize+self.WallThickness+self.Margin); error != nil {
+			return error
 		}
-		xBase := x*cellSize + bounds.Min.X
-		DrawRect(img, image.Rect(
-			xBase,
-			yBase,
-			xBase+lastWidth,
-			yBase+wallThickness), wallColor)
 	}
+	return out.Close()
+}
 
-	DrawRect(img, image.Rectangle{
-		bounds.Max.Add(image.Pt(-wallThickness, -wallThickness)),
-		bounds.Max,
-	}, wallColor)
+// writeWindow paints the pixel rows from top to bottom of the picture of a
+// board made of the given rows of fields.
+func (self Style) writeWindow(out *pngWriter, window [][]board.Field, top, bottom int) os.Error {
+	b := board.New(len(window[0]), len(window))
+	for y, row := range window {
+		for x, field := range row {
+			b.At(x, y).SetDirection(field.Direction())
+		}
+	}
+	img := self.paintRegion(b, image.Rect(0, top, out.width, bottom), noFill, false, nil)
+	for y := 0; y < bottom-top; y++ {
+		if error := out.WriteRow(img.Pix[y*img.Stride:]); error != nil {
+			return error
+		}
+	}
+	return nil
+}
 
-	return img
+func fillLine(line []uint8, from, to int, color image.RGBAColor) {
+	for x := from; x < to; x++ {
+		line[4*x] = color.R
+		line[4*x+1] = color.G
+		line[4*x+2] = color.B
+		line[4*x+3] = color.A
+	}
 }
@@ -0,0 +1,305 @@
+package painter
+
+import (
+	"board"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"strings"
+)
+
+var (
+	currentColor     = image.RGBAColor{0xff, 0, 0, 0xff}
+	visitedColor     = image.RGBAColor{0xa0, 0xc8, 0xff, 0xff}
+	backtrackedColor = image.RGBAColor{0xc0, 0xc0, 0xc0, 0xff}
+)
+
+// Palette indices of the animation frames.
+const (
+	boardIndex = iota
+	wallIndex
+	pathIndex
+	currentIndex
+	visitedIndex
+	backtrackedIndex
+)
+
+// animationPalette returns the colours of the palette indices, taking the
+// colours of the board, walls and path from the style.
+func (self Style) animationPalette() []image.RGBAColor {
+	return []image.RGBAColor{self.BoardColor, self.WallColor, self.PathColor,
+		currentColor, visitedColor, backtrackedColor}
+}
+
+// Frame delays in hundredths of a second.
+const (
+	frameDelay      = 5
+	finalFrameDelay = 300
+)
+
+// frameWriter stores the frames of an animation, given as one palette index
+// per pixel.
+type frameWriter interface {
+	WriteFrame(pixels []uint8, delay int) os.Error
+	Close() os.Error
+}
+
+// animation draws frames looking like the pictures of Style.Paint, with the
+// interior of each field filled with a colour from the animation palette.
+type animation struct {
+	frames        frameWriter
+	style         Style
+	width, height int
+	pixels        []uint8
+}
+
+func (self Style) newGIFAnimation(w io.Writer, b board.Board) (*animation, os.Error) {
+	size := self.imageSize(b)
+	gif, error := newGIFWriter(w, size.X, size.Y, self.animationPalette())
+	if error != nil {
+		return nil, error
+	}
+	return self.newAnimation(gif, b), nil
+}
+
+func (self Style) newAnimation(frames frameWriter, b board.Board) *animation {
+	size := self.imageSize(b)
+	return &animation{
+		frames: frames,
+		style:  self,
+		width:  size.X,
+		height: size.Y,
+		pixels: make([]uint8, size.X*size.Y),
+	}
+}
+
+func (self *animation) fill(rect image.Rectangle, index uint8) {
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		line := self.pixels[y*self.width:]
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			line[x] = index
+		}
+	}
+}
+
+// writeFrame draws the board with the fields filled according to cells,
+// which may be nil.
+func (self *animation) writeFrame(b board.Board, cells [][]uint8, delay int) os.Error {
+	style := self.style
+	cellSize, wallThickness := style.CellSize, style.WallThickness
+	self.fill(image.Rect(0, 0, self.width, self.height), boardIndex)
+	for y := 0; y <= b.Height(); y++ {
+		for x := 0; x <= b.Width(); x++ {
+			base := image.Pt(style.Margin+x*cellSize, style.Margin+y*cellSize)
+			if cells != nil && x < b.Width() && y < b.Height() && cells[y][x] != boardIndex {
+				self.fill(image.Rect(base.X+wallThickness, base.Y+wallThickness,
+					base.X+cellSize, base.Y+cellSize), cells[y][x])
+			}
+			inPost := style.postShape(b, image.Pt(x, y))
+			for dy := 0; dy < wallThickness; dy++ {
+				for dx := 0; dx < wallThickness; dx++ {
+					if inPost(dx, dy) {
+						self.pixels[(base.Y+dy)*self.width+base.X+dx] = wallIndex
+					}
+				}
+			}
+			if x < b.Width() && !hasPassage(b, x, y, board.N) {
+				self.fill(image.Rect(base.X+wallThickness, base.Y,
+					base.X+cellSize, base.Y+wallThickness), wallIndex)
+			}
+			if y < b.Height() && !hasPassage(b, x, y, board.W) {
+				self.fill(image.Rect(base.X, base.Y+wallThickness,
+					base.X+wallThickness, base.Y+cellSize), wallIndex)
+			}
+		}
+	}
+	return self.frames.WriteFrame(self.pixels, delay)
+}
+
+func newCells(width, height int) [][]uint8 {
+	cells := make([][]uint8, height)
+	for y := range cells {
+		cells[y] = make([]uint8, width)
+	}
+	return cells
+}
+
+// GenerationAnimation records the progress of a generation algorithm as an
+// animated GIF. It is meant to be passed as the observer of the algorithm;
+// every given number of steps it draws a frame of the maze carved so far,
+// with the frontier of the algorithm and the last carved field highlighted.
+type GenerationAnimation struct {
+	*animation
+	board        board.Board
+	cells        [][]uint8
+	frontier     []image.Point
+	last         image.Point
+	every, steps int
+}
+
+// NewGenerationAnimation starts an animation drawn in the colours, sizes and
+// margin of the style.
+func (self Style) NewGenerationAnimation(w io.Writer, width, height,
+	every int) (*GenerationAnimation, os.Error) {
+	if width < 1 || height < 1 {
+		return nil, fmt.Errorf("Invalid board size: %dx%d", width, height)
+	}
+	b := board.New(width, height)
+	animation, error := self.newGIFAnimation(w, b)
+	if error != nil {
+		return nil, error
+	}
+	if every < 1 {
+		every = 1
+	}
+	return &GenerationAnimation{
+		animation: animation,
+		board:     b,
+		cells:     newCells(width, height),
+		last:      image.Pt(-1, -1),
+		every:     every,
+	}, nil
+}
+
+func (self *GenerationAnimation) Carved(p image.Point, dir board.Direction) {
+	delta, _ := dir.Delta()
+	q := p.Add(delta)
+	self.board.At(p.X, p.Y).AddDirection(dir)
+	self.board.At(q.X, q.Y).AddDirection(dir.Opposite())
+	self.last = q
+	self.step()
+}
+
+func (self *GenerationAnimation) Walled(p image.Point, dir board.Direction) {
+	delta, _ := dir.Delta()
+	q := p.Add(delta)
+	field := self.board.At(p.X, p.Y)
+	field.SetDirection(field.Direction() &^ dir)
+	field = self.board.At(q.X, q.Y)
+	field.SetDirection(field.Direction() &^ dir.Opposite())
+	self.last = p
+	self.step()
+}
+
+func (self *GenerationAnimation) Frontier(fields []image.Point) {
+	self.frontier = append(self.frontier[:0], fields...)
+}
+
+func (self *GenerationAnimation) step() {
+	self.steps++
+	if self.steps%self.every != 0 {
+		return
+	}
+	for _, row := range self.cells {
+		for x := range row {
+			row[x] = boardIndex
+		}
+	}
+	for _, p := range self.frontier {
+		self.cells[p.Y][p.X] = pathIndex
+	}
+	self.cells[self.last.Y][self.last.X] = currentIndex
+	self.writeFrame(self.board, self.cells, frameDelay)
+}
+
+// Close draws the final board, which may differ from the observed one by the
+// openings of the entrance and exit, and finishes the animation.
+func (self *GenerationAnimation) Close(final board.Board) os.Error {
+	self.writeFrame(final, nil, finalFrameDelay)
+	return self.frames.Close()
+}
+
+// SolveAnimation records the progress of a solver, passed to it as the
+// observer, either as an animated GIF or as a sequence of PNG pictures. Fields
+// the solver has been to are drawn in light blue, fields it backtracked from
+// in grey and the field it entered last in red; the last frame shows the path
+// found.
+type SolveAnimation struct {
+	*animation
+	board        board.Board
+	cells        [][]uint8
+	last         image.Point
+	every, steps int
+	goalFound    bool
+}
+
+// NewSolveAnimation starts an animation drawn in the colours, sizes and margin
+// of the style.
+func (self Style) NewSolveAnimation(w io.Writer, b board.Board,
+	every int) (*SolveAnimation, os.Error) {
+	animation, error := self.newGIFAnimation(w, b)
+	if error != nil {
+		return nil, error
+	}
+	return newSolveAnimation(animation, b, every), nil
+}
+
+// NewSolvePNGSequence starts an animation drawn in the style, writing every
+// frame to a file of its own, named by formatting the frame number with the
+// pattern, such as "frame%04d.png".
+func (self Style) NewSolvePNGSequence(pattern string, b board.Board,
+	every int) (*SolveAnimation, os.Error) {
+	if !strings.Contains(pattern, "%") {
+		return nil, os.NewError("Pattern of the file names has no frame number: " + pattern)
+	}
+	size := self.imageSize(b)
+	frames := &pngSequence{
+		pattern: pattern,
+		width:   size.X,
+		height:  size.Y,
+		palette: self.animationPalette(),
+	}
+	return newSolveAnimation(self.newAnimation(frames, b), b, every), nil
+}
+
+func newSolveAnimation(animation *animation, b board.Board, every int) *SolveAnimation {
+	if every < 1 {
+		every = 1
+	}
+	return &SolveAnimation{
+		animation: animation,
+		board:     b,
+		cells:     newCells(b.Width(), b.Height()),
+		last:      image.Pt(-1, -1),
+		every:     every,
+	}
+}
+
+func (self *SolveAnimation) Entered(p image.Point) {
+	if self.last.X >= 0 && self.cells[self.last.Y][self.last.X] == currentIndex {
+		self.cells[self.last.Y][self.last.X] = visitedIndex
+	}
+	self.cells[p.Y][p.X] = currentIndex
+	self.last = p
+	self.step()
+}
+
+func (self *SolveAnimation) Backtracked(p image.Point) {
+	self.cells[p.Y][p.X] = backtrackedIndex
+	self.step()
+}
+
+func (self *SolveAnimation) GoalFound(path board.Path) {
+	for _, p := range path {
+		self.cells[p.Y][p.X] = pathIndex
+	}
+	self.goalFound = true
+	self.writeFrame(self.board, self.cells, finalFrameDelay)
+}
+
+func (self *SolveAnimation) step() {
+	self.steps++
+	if self.steps%self.every == 0 {
+		self.writeFrame(self.board, self.cells, frameDelay)
+	}
+}
+
+// Close finishes the animation. If the solver didn't find the exit, the
+// last state of the search is shown.
+func (self *SolveAnimation) Close() os.Error {
+	if !self.goalFound {
+		self.writeFrame(self.board, self.cells, finalFrameDelay)
+	}
+	return self.frames.Close()
+}
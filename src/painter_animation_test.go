@@ -0,0 +1,181 @@
+package painter
+
+import (
+	"board"
+	"bytes"
+	"fmt"
+	"generator"
+	"image"
+	"image/gif"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path"
+	"rand"
+	"testing"
+)
+
+func TestAnimatingGeneration(t *testing.T) {
+	const width, height, every = 6, 5, 4
+	const cellSize, wallThickness = 5, 2
+	var buf bytes.Buffer
+	style := DefaultStyle
+	style.CellSize, style.WallThickness = cellSize, wallThickness
+	animation, error := style.NewGenerationAnimation(&buf, width, height, every)
+	if error != nil {
+		t.Fatalf("Unable to start the animation: %v", error)
+	}
+	algorithm, _ := generator.Lookup("backtracker")
+	b := algorithm.(generator.ObservableAlgorithm).GenerateObserved(width, height,
+		rand.New(rand.NewSource(0)), animation)
+	if error := animation.Close(b); error != nil {
+		t.Fatalf("Unable to finish the animation: %v", error)
+	}
+
+	decoded, error := gif.DecodeAll(&buf)
+	if error != nil {
+		t.Fatalf("Unable to decode the animation: %v", error)
+	}
+	// A backtracker carves a passage into every field but the first one.
+	if frames := (width*height-1)/every + 1; len(decoded.Image) != frames {
+		t.Errorf("Got %d frames, expected %d", len(decoded.Image), frames)
+	}
+	final := decoded.Image[len(decoded.Image)-1]
+	painted := Paint(b, nil, cellSize, wallThickness)
+	if !final.Bounds().Eq(painted.Bounds()) {
+		t.Fatalf("Final frame bounds are %v, expected %v",
+			final.Bounds(), painted.Bounds())
+	}
+	bounds := painted.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r1, g1, b1, _ := final.At(x, y).RGBA()
+			r2, g2, b2, _ := painted.At(x, y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 {
+				t.Fatalf("Pixel %v of the final frame differs from the painted image",
+					image.Pt(x, y))
+			}
+		}
+	}
+}
+
+func TestAnimatingWithStyle(t *testing.T) {
+	style := DefaultStyle
+	if error := style.Parse(testStyle); error != nil {
+		t.Fatalf("Unable to parse the style: %v", error)
+	}
+	var buf bytes.Buffer
+	animation, error := style.NewGenerationAnimation(&buf, 6, 5, 10)
+	if error != nil {
+		t.Fatalf("Unable to start the animation: %v", error)
+	}
+	b := generator.Generate(6, 5, rand.New(rand.NewSource(0)))
+	if error := animation.Close(b); error != nil {
+		t.Fatalf("Unable to finish the animation: %v", error)
+	}
+	decoded, error := gif.DecodeAll(&buf)
+	if error != nil {
+		t.Fatalf("Unable to decode the animation: %v", error)
+	}
+	if !sameColors(decoded.Image[len(decoded.Image)-1], style.Paint(b, nil, nil)) {
+		t.Errorf("Final frame differs from the painted image")
+	}
+}
+
+// countingSolveObserver counts the events it is notified of.
+type countingSolveObserver struct {
+	events int
+}
+
+func (self *countingSolveObserver) Entered(p image.Point)     { self.events++ }
+func (self *countingSolveObserver) Backtracked(p image.Point) { self.events++ }
+func (self *countingSolveObserver) GoalFound(path board.Path) {}
+
+func TestAnimatingSolving(t *testing.T) {
+	const cellSize, wallThickness, every = 6, 2, 3
+	style := DefaultStyle
+	style.CellSize, style.WallThickness = cellSize, wallThickness
+	b := generator.Generate(8, 6, rand.New(rand.NewSource(0)))
+	for _, name := range board.SolverNames() {
+		solver, _ := board.LookupSolver(name)
+		counter := &countingSolveObserver{}
+		solver.Solve(b, counter)
+
+		var buf bytes.Buffer
+		animation, error := style.NewSolveAnimation(&buf, b, every)
+		if error != nil {
+			t.Fatalf("Unable to start the animation: %v", error)
+		}
+		solution, error := solver.Solve(b, animation)
+		if error != nil {
+			t.Fatalf("Solver %s failed: %v", name, error)
+		}
+		if error := animation.Close(); error != nil {
+			t.Fatalf("Unable to finish the animation: %v", error)
+		}
+		decoded, error := gif.DecodeAll(&buf)
+		if error != nil {
+			t.Fatalf("Unable to decode the animation of %s: %v", name, error)
+		}
+		if frames := counter.events/every + 1; len(decoded.Image) != frames {
+			t.Errorf("Got %d frames of %s, expected %d",
+				len(decoded.Image), name, frames)
+		}
+		final := decoded.Image[len(decoded.Image)-1]
+		for _, p := range solution {
+			center := image.Pt(p.X*cellSize+cellSize/2+1, p.Y*cellSize+cellSize/2+1)
+			red, green, blue, _ := final.At(center.X, center.Y).RGBA()
+			if red>>8 != uint32(pathColor.R) || green>>8 != uint32(pathColor.G) ||
+				blue>>8 != uint32(pathColor.B) {
+				t.Errorf("Field %v on the path of %s is not highlighted", p, name)
+			}
+		}
+	}
+}
+
+func TestWritingSolvePNGSequence(t *testing.T) {
+	dir, error := ioutil.TempDir("", "painter")
+	if error != nil {
+		t.Fatalf("Unable to create a temporary directory: %v", error)
+	}
+	defer os.RemoveAll(dir)
+	b := generator.Generate(4, 3, rand.New(rand.NewSource(0)))
+	pattern := path.Join(dir, "frame%03d.png")
+	style := DefaultStyle
+	style.CellSize, style.WallThickness = 5, 1
+	animation, error := style.NewSolvePNGSequence(pattern, b, 1)
+	if error != nil {
+		t.Fatalf("Unable to start the animation: %v", error)
+	}
+	solver, _ := board.LookupSolver("dfs")
+	counter := &countingSolveObserver{}
+	solver.Solve(b, counter)
+	solver.Solve(b, animation)
+	if error := animation.Close(); error != nil {
+		t.Fatalf("Unable to finish the animation: %v", error)
+	}
+	for frame := 1; frame <= counter.events+1; frame++ {
+		file, error := os.Open(fmt.Sprintf(pattern, frame))
+		if error != nil {
+			t.Fatalf("Frame %d is missing: %v", frame, error)
+		}
+		img, error := png.Decode(file)
+		file.Close()
+		if error != nil {
+			t.Fatalf("Unable to decode frame %d: %v", frame, error)
+		}
+		if !img.Bounds().Eq(image.Rect(0, 0, 21, 16)) {
+			t.Errorf("Frame %d has bounds %v", frame, img.Bounds())
+		}
+	}
+	if _, error := os.Stat(fmt.Sprintf(pattern, counter.events+2)); error == nil {
+		t.Errorf("Too many frames were written")
+	}
+}
+
+func TestSolvePNGSequenceNeedsFrameNumber(t *testing.T) {
+	b := generator.Generate(4, 3, rand.New(rand.NewSource(0)))
+	if _, error := DefaultStyle.NewSolvePNGSequence("frame.png", b, 1); error == nil {
+		t.Errorf("Pattern without a frame number was accepted")
+	}
+}
@@ -0,0 +1,138 @@
+package painter
+
+import (
+	"bufio"
+	"compress/lzw"
+	"image"
+	"io"
+	"os"
+)
+
+// gifWriter encodes an animated GIF frame by frame. Frames are given as one
+// palette index per pixel and always cover the whole picture.
+type gifWriter struct {
+	w             *bufio.Writer
+	width, height int
+	paletteBits   int
+	error         os.Error
+}
+
+func newGIFWriter(w io.Writer, width, height int, palette []image.RGBAColor) (*gifWriter, os.Error) {
+	self := &gifWriter{w: bufio.NewWriter(w), width: width, height: height, paletteBits: 1}
+	for 1<<uint(self.paletteBits) < len(palette) {
+		self.paletteBits++
+	}
+	header := []byte("GIF89a")
+	header = appendUint16(header, width)
+	header = appendUint16(header, height)
+	bits := uint8(self.paletteBits - 1)
+	header = append(header, 0x80|bits<<4|bits, 0, 0)
+	for i := 0; i < 1<<uint(self.paletteBits); i++ {
+		var color image.RGBAColor
+		if i < len(palette) {
+			color = palette[i]
+		}
+		header = append(header, color.R, color.G, color.B)
+	}
+	// Application extension making the animation loop forever.
+	header = append(header, 0x21, 0xff, 11)
+	header = append(header, []byte("NETSCAPE2.0")...)
+	header = append(header, 3, 1, 0, 0, 0)
+	self.write(header)
+	return self, self.error
+}
+
+func appendUint16(data []byte, value int) []byte {
+	return append(data, uint8(value), uint8(value>>8))
+}
+
+func (self *gifWriter) write(data []byte) {
+	if self.error == nil {
+		_, self.error = self.w.Write(data)
+	}
+}
+
+// WriteFrame writes a frame shown for the given delay, in hundredths of a
+// second.
+func (self *gifWriter) WriteFrame(pixels []uint8, delay int) os.Error {
+	control := []byte{0x21, 0xf9, 4, 0}
+	control = appendUint16(control, delay)
+	control = append(control, 0, 0)
+	self.write(control)
+
+	descriptor := []byte{0x2c, 0, 0, 0, 0}
+	descriptor = appendUint16(descriptor, self.width)
+	descriptor = appendUint16(descriptor, self.height)
+	descriptor = append(descriptor, 0)
+	self.write(descriptor)
+
+	codeSize := self.paletteBits
+	if codeSize < 2 {
+		codeSize = 2
+	}
+	self.write([]byte{uint8(codeSize)})
+	if self.error != nil {
+		return self.error
+	}
+	blocks := &gifBlockWriter{w: self.w}
+	compressor := lzw.NewWriter(blocks, lzw.LSB, codeSize)
+	if _, self.error = compressor.Write(pixels[:self.width*self.height]); self.error != nil {
+		return self.error
+	}
+	if self.error = compressor.Close(); self.error != nil {
+		return self.error
+	}
+	self.error = blocks.Close()
+	return self.error
+}
+
+func (self *gifWriter) Close() os.Error {
+	self.write([]byte{0x3b})
+	if self.error != nil {
+		return self.error
+	}
+	return self.w.Flush()
+}
+
+// gifBlockWriter splits the image data into sub-blocks of at most 255 bytes,
+// each preceded by its length.
+type gifBlockWriter struct {
+	w     io.Writer
+	block [256]byte
+	n     int
+}
+
+func (self *gifBlockWriter) Write(data []byte) (int, os.Error) {
+	written := 0
+	for len(data) > 0 {
+		n := copy(self.block[1+self.n:], data)
+		self.n += n
+		written += n
+		data = data[n:]
+		if self.n == 255 {
+			if error := self.flush(); error != nil {
+				return written, error
+			}
+		}
+	}
+	return written, nil
+}
+
+func (self *gifBlockWriter) flush() os.Error {
+	if self.n == 0 {
+		return nil
+	}
+	self.block[0] = uint8(self.n)
+	_, error := self.w.Write(self.block[:1+self.n])
+	self.n = 0
+	return error
+}
+
+// Close writes the remaining data and the block terminator.
+func (self *gifBlockWriter) Close() os.Error {
+	if error := self.flush(); error != nil {
+		return error
+	}
+	_, error := self.w.Write([]byte{0})
+	return error
+}
@@ -0,0 +1,89 @@
+package painter
+
+import (
+	"board"
+	"image"
+	"os"
+	"sort"
+)
+
+// Palette is a colour gradient passing through evenly spaced colours.
+type Palette []image.RGBAColor
+
+// At returns the colour at the position t of the gradient, from 0 to 1.
+func (self Palette) At(t float64) image.RGBAColor {
+	if t <= 0 || len(self) == 1 {
+		return self[0]
+	}
+	if t >= 1 {
+		return self[len(self)-1]
+	}
+	position := t * float64(len(self)-1)
+	i := int(position)
+	fraction := position - float64(i)
+	from, to := self[i], self[i+1]
+	mix := func(a, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*fraction + 0.5)
+	}
+	return image.RGBAColor{mix(from.R, to.R), mix(from.G, to.G),
+		mix(from.B, to.B), mix(from.A, to.A)}
+}
+
+var palettes map[string]Palette = map[string]Palette{
+	"heat": {
+		{0x80, 0, 0, 0xff}, {0xff, 0, 0, 0xff},
+		{0xff, 0xd0, 0, 0xff}, {0xff, 0xff, 0xe0, 0xff},
+	},
+	"viridis": {
+		{0x44, 0x01, 0x54, 0xff}, {0x3b, 0x52, 0x8b, 0xff}, {0x21, 0x90, 0x8d, 0xff},
+		{0x5d, 0xc9, 0x63, 0xff}, {0xfd, 0xe7, 0x25, 0xff},
+	},
+	"grayscale": {{0xf0, 0xf0, 0xf0, 0xff}, {0x40, 0x40, 0x40, 0xff}},
+	"rainbow": {
+		{0xff, 0, 0, 0xff}, {0xff, 0xff, 0, 0xff}, {0, 0xff, 0, 0xff},
+		{0, 0xff, 0xff, 0xff}, {0, 0, 0xff, 0xff}, {0xff, 0, 0xff, 0xff},
+	},
+}
+
+func LookupPalette(name string) (Palette, os.Error) {
+	palette, ok := palettes[name]
+	if !ok {
+		return nil, os.NewError("Unknown palette " + name)
+	}
+	return palette, nil
+}
+
+func PaletteNames() []string {
+	names := make([]string, 0, len(palettes))
+	for name := range palettes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PaintHeatmap renders the board like Paint does, with each field and the
+// passages leading to it filled with the colour of the palette matching its
+// distance, relative to the largest one. Fields with a negative distance, such
+// as the unreachable ones of a board.DistanceMap, are left empty.
+func (self Style) PaintHeatmap(b board.Board, distances [][]int, palette Palette,
+	path board.Path) image.Image {
+	max := 0
+	for _, row := range distances {
+		for _, distance := range row {
+			if distance > max {
+				max = distance
+			}
+		}
+	}
+	return self.paint(b, func(x, y int) (image.RGBAColor, bool) {
+		distance := distances[y][x]
+		if distance < 0 {
+			return self.BoardColor, false
+		}
+		if max == 0 {
+			return palette.At(0), true
+		}
+		return palette.At(float64(distance) / float64(max)), true
+	}, true, path)
+}
@@ -0,0 +1,63 @@
+package painter
+
+import (
+	"image"
+	"testing"
+)
+
+func TestPaletteGradient(t *testing.T) {
+	palette := Palette{{0, 0, 0, 0xff}, {0xff, 0x80, 0, 0xff}, {0xff, 0xff, 0xff, 0xff}}
+	tests := []struct {
+		T        float64
+		Expected image.RGBAColor
+	}{
+		{-1, palette[0]}, {0, palette[0]}, {0.25, image.RGBAColor{0x80, 0x40, 0, 0xff}},
+		{0.5, palette[1]}, {1, palette[2]}, {2, palette[2]},
+	}
+	for _, test := range tests {
+		color := palette.At(test.T)
+		if color.R != test.Expected.R || color.G != test.Expected.G ||
+			color.B != test.Expected.B || color.A != test.Expected.A {
+			t.Errorf("Colour at %v is %v, expected %v", test.T, color, test.Expected)
+		}
+	}
+}
+
+func TestLookingUpPalettes(t *testing.T) {
+	for _, name := range PaletteNames() {
+		if palette, error := LookupPalette(name); error != nil || len(palette) < 2 {
+			t.Errorf("Palette %s is invalid: %v", name, error)
+		}
+	}
+	if _, error := LookupPalette("no-such-palette"); error == nil {
+		t.Errorf("Looking up an unknown palette succeeded")
+	}
+}
+
+func TestPaintingHeatmap(t *testing.T) {
+	b := smallBoard()
+	distanceMap, _ := b.Distances(*b.Entrance(), nil)
+	distanceMap.Distances[0][1] = -1
+	palette, _ := LookupPalette("viridis")
+	img := DefaultStyle.PaintHeatmap(b, distanceMap.Distances, palette, nil)
+	// The fields are (0, 0), (0, 1) and (1, 1) at the distances 0, 1 and 2
+	// from the entrance; (1, 0) is treated as unreachable.
+	for _, field := range []struct {
+		X, Y     int
+		Expected image.RGBAColor
+	}{
+		{0, 0, palette.At(0)}, {0, 1, palette.At(0.5)},
+		{1, 1, palette.At(1)}, {1, 0, DefaultStyle.BoardColor},
+	} {
+		r, g, b, _ := img.At(field.X*10+6, field.Y*10+6).RGBA()
+		if r>>8 != uint32(field.Expected.R) || g>>8 != uint32(field.Expected.G) ||
+			b>>8 != uint32(field.Expected.B) {
+			t.Errorf("Field (%d, %d) is not filled with %v", field.X, field.Y, field.Expected)
+		}
+	}
+	// The passage from (0, 0) to (0, 1) has the colour of the latter.
+	r, g, _, _ := img.At(6, 10).RGBA()
+	if expected := palette.At(0.5); r>>8 != uint32(expected.R) || g>>8 != uint32(expected.G) {
+		t.Errorf("Passage to (0, 1) is not filled")
+	}
+}
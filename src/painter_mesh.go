@@ -0,0 +1,232 @@
+package painter
+
+import (
+	"board"
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// MeshStyle gives the dimensions of a printed maze in millimetres. The walls
+// stand on a base plate covering the whole board.
+type MeshStyle struct {
+	CellSize, WallThickness float64
+	WallHeight, BaseDepth   float64
+}
+
+var DefaultMeshStyle = MeshStyle{
+	CellSize:      10,
+	WallThickness: 2,
+	WallHeight:    8,
+	BaseDepth:     2,
+}
+
+func (self MeshStyle) Validate() os.Error {
+	switch {
+	case self.WallThickness <= 0 || self.WallHeight <= 0 || self.BaseDepth <= 0:
+		return os.NewError("Wall thickness, wall height and base depth have to be positive")
+	case self.CellSize <= self.WallThickness:
+		return fmt.Errorf("Cell size %g has to be larger than the wall thickness %g",
+			self.CellSize, self.WallThickness)
+	}
+	return nil
+}
+
+type Vector struct {
+	X, Y, Z float64
+}
+
+func (self Vector) Sub(other Vector) Vector {
+	return Vector{self.X - other.X, self.Y - other.Y, self.Z - other.Z}
+}
+
+func (self Vector) Cross(other Vector) Vector {
+	return Vector{
+		self.Y*other.Z - self.Z*other.Y,
+		self.Z*other.X - self.X*other.Z,
+		self.X*other.Y - self.Y*other.X,
+	}
+}
+
+func (self Vector) Dot(other Vector) float64 {
+	return self.X*other.X + self.Y*other.Y + self.Z*other.Z
+}
+
+// Mesh is a solid given by triangles, which index its vertices and are
+// ordered counter-clockwise when seen from the outside.
+type Mesh struct {
+	Vertices  []Vector
+	Triangles [][3]int
+}
+
+// meshBuilder splits the solid into boxes of a grid. Along each horizontal
+// axis, the grid alternates between lines of posts as thick as the walls and
+// the gaps between them, like the pixels of a text picture of the board; the
+// base plate and the walls form two layers. Faces are only made between
+// filled and empty boxes, so that the surface is closed, and vertices on the
+// grid lines are shared.
+type meshBuilder struct {
+	mesh       *Mesh
+	xs, ys, zs []float64
+	filled     [][]bool
+	vertices   map[int]int
+}
+
+// Mesh extrudes the walls of the board, as drawn by Paint, into a solid that
+// can be printed. The board is seen from above, with its first row along the
+// far side of the plate.
+func (self MeshStyle) Mesh(b board.Board) (*Mesh, os.Error) {
+	if error := self.Validate(); error != nil {
+		return nil, error
+	}
+	width, height := 2*b.Width()+1, 2*b.Height()+1
+	builder := &meshBuilder{
+		mesh:     &Mesh{},
+		xs:       make([]float64, width+1),
+		ys:       make([]float64, height+1),
+		zs:       []float64{0, self.BaseDepth, self.BaseDepth + self.WallHeight},
+		filled:   make([][]bool, height),
+		vertices: make(map[int]int),
+	}
+	gridLine := func(i int) float64 {
+		return float64(i/2)*self.CellSize + float64(i%2)*self.WallThickness
+	}
+	for i := range builder.xs {
+		builder.xs[i] = gridLine(i)
+	}
+	for j := range builder.ys {
+		builder.ys[j] = gridLine(height) - gridLine(j)
+	}
+	for j := range builder.filled {
+		builder.filled[j] = make([]bool, width)
+		for i := 0; i < width; i += 2 {
+			builder.filled[j][i] = j%2 == 0
+		}
+	}
+	for _, wall := range Walls(b) {
+		for x := 2 * wall.From.X; x <= 2*wall.To.X; x++ {
+			for y := 2 * wall.From.Y; y <= 2*wall.To.Y; y++ {
+				builder.filled[y][x] = true
+			}
+		}
+	}
+
+	for k := 0; k < 2; k++ {
+		for j := 0; j < height; j++ {
+			for i := 0; i < width; i++ {
+				if builder.isFilled(i, j, k) {
+					builder.addBox(i, j, k)
+				}
+			}
+		}
+	}
+	return builder.mesh, nil
+}
+
+// isFilled tells whether the box of the grid belongs to the solid; k is 0
+// for the base plate and 1 for the walls.
+func (self *meshBuilder) isFilled(i, j, k int) bool {
+	if k < 0 || k > 1 || j < 0 || j >= len(self.filled) || i < 0 || i >= len(self.filled[j]) {
+		return false
+	}
+	return k == 0 || self.filled[j][i]
+}
+
+// addBox adds the faces of the box which are on the surface of the solid.
+func (self *meshBuilder) addBox(i, j, k int) {
+	for _, side := range [][3]int{{1, 0, 0}, {-1, 0, 0}, {0, 1, 0}, {0, -1, 0}, {0, 0, 1}, {0, 0, -1}} {
+		if self.isFilled(i+side[0], j+side[1], k+side[2]) {
+			continue
+		}
+		// The corners of the face, going around it.
+		corners := make([][3]int, 0, 4)
+		for _, c := range [][2]int{{0, 0}, {1, 0}, {1, 1}, {0, 1}} {
+			corner := [3]int{i, j, k}
+			free := 0
+			for axis := 0; axis < 3; axis++ {
+				switch {
+				case side[axis] > 0:
+					corner[axis]++
+				case side[axis] == 0:
+					corner[axis] += c[free]
+					free++
+				}
+			}
+			corners = append(corners, corner)
+		}
+		self.addQuad(corners, Vector{float64(side[0]), float64(side[1]), float64(side[2])})
+	}
+}
+
+// addQuad adds two triangles covering the face, turning them to face the
+// outward direction.
+func (self *meshBuilder) addQuad(corners [][3]int, outward Vector) {
+	indices := make([]int, 4)
+	for n, corner := range corners {
+		indices[n] = self.vertex(corner[0], corner[1], corner[2])
+	}
+	v := self.mesh.Vertices
+	a, b, c := v[indices[0]], v[indices[1]], v[indices[2]]
+	// The grid axes are turned into the coordinates of the mesh, with y
+	// pointing the other way, so the outward direction is compared in the
+	// coordinates of the mesh.
+	outward.Y = -outward.Y
+	if b.Sub(a).Cross(c.Sub(a)).Dot(outward) < 0 {
+		indices[1], indices[3] = indices[3], indices[1]
+	}
+	self.mesh.Triangles = append(self.mesh.Triangles,
+		[3]int{indices[0], indices[1], indices[2]},
+		[3]int{indices[0], indices[2], indices[3]})
+}
+
+func (self *meshBuilder) vertex(i, j, k int) int {
+	key := (k*len(self.ys)+j)*len(self.xs) + i
+	if index, ok := self.vertices[key]; ok {
+		return index
+	}
+	index := len(self.mesh.Vertices)
+	self.mesh.Vertices = append(self.mesh.Vertices, Vector{self.xs[i], self.ys[j], self.zs[k]})
+	self.vertices[key] = index
+	return index
+}
+
+// WriteSTL writes the mesh in the binary STL format.
+func (self *Mesh) WriteSTL(w io.Writer) os.Error {
+	out := bufio.NewWriter(w)
+	header := make([]byte, 84)
+	copy(header, "Maze")
+	binary.LittleEndian.PutUint32(header[80:], uint32(len(self.Triangles)))
+	out.Write(header)
+	record := make([]byte, 50)
+	for _, triangle := range self.Triangles {
+		a, b, c := self.Vertices[triangle[0]], self.Vertices[triangle[1]], self.Vertices[triangle[2]]
+		normal := b.Sub(a).Cross(c.Sub(a))
+		length := math.Sqrt(normal.Dot(normal))
+		normal = Vector{normal.X / length, normal.Y / length, normal.Z / length}
+		for n, v := range []Vector{normal, a, b, c} {
+			for m, value := range []float64{v.X, v.Y, v.Z} {
+				binary.LittleEndian.PutUint32(record[12*n+4*m:], math.Float32bits(float32(value)))
+			}
+		}
+		if _, error := out.Write(record); error != nil {
+			return error
+		}
+	}
+	return out.Flush()
+}
+
+// WriteOBJ writes the mesh in the Wavefront OBJ format.
+func (self *Mesh) WriteOBJ(w io.Writer) os.Error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "# Maze\n")
+	for _, v := range self.Vertices {
+		fmt.Fprintf(out, "v %g %g %g\n", v.X, v.Y, v.Z)
+	}
+	for _, triangle := range self.Triangles {
+		fmt.Fprintf(out, "f %d %d %d\n", triangle[0]+1, triangle[1]+1, triangle[2]+1)
+	}
+	return out.Flush()
+}
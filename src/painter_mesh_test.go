@@ -0,0 +1,90 @@
+package painter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"generator"
+	"math"
+	"rand"
+	"strings"
+	"testing"
+)
+
+// TestMeshIsWatertight checks that every edge of the mesh is shared by
+// exactly two triangles, going along it in opposite directions, and that the
+// volume enclosed is that of the base plate and the walls.
+func TestMeshIsWatertight(t *testing.T) {
+	b := generator.Generate(7, 5, rand.New(rand.NewSource(0)))
+	style := DefaultMeshStyle
+	mesh, error := style.Mesh(b)
+	if error != nil {
+		t.Fatalf("Unable to build the mesh: %v", error)
+	}
+	n := len(mesh.Vertices)
+	edges := make(map[int]int)
+	volume := 0.0
+	for _, triangle := range mesh.Triangles {
+		for i := 0; i < 3; i++ {
+			edges[triangle[i]*n+triangle[(i+1)%3]]++
+		}
+		a, b, c := mesh.Vertices[triangle[0]], mesh.Vertices[triangle[1]], mesh.Vertices[triangle[2]]
+		volume += a.Dot(b.Cross(c)) / 6
+	}
+	for key, count := range edges {
+		from, to := key/n, key%n
+		if count != 1 || edges[to*n+from] != 1 {
+			t.Fatalf("Edge from %v to %v is used %d times, the opposite one %d times",
+				mesh.Vertices[from], mesh.Vertices[to], count, edges[to*n+from])
+		}
+	}
+
+	// The walls cover the posts and the sides of the fields without a
+	// passage, each side counted once.
+	width := 7*style.CellSize + style.WallThickness
+	depth := 5*style.CellSize + style.WallThickness
+	gap := style.CellSize - style.WallThickness
+	wallArea := 8 * 6 * style.WallThickness * style.WallThickness
+	for _, wall := range Walls(b) {
+		length := wall.To.X - wall.From.X + wall.To.Y - wall.From.Y
+		wallArea += float64(length) * gap * style.WallThickness
+	}
+	expected := width*depth*style.BaseDepth + wallArea*style.WallHeight
+	if math.Fabs(volume-expected) > 1e-6 {
+		t.Errorf("Volume of the mesh is %v, expected %v", volume, expected)
+	}
+}
+
+func TestWritingMesh(t *testing.T) {
+	b := generator.Generate(3, 2, rand.New(rand.NewSource(0)))
+	mesh, _ := DefaultMeshStyle.Mesh(b)
+	var stl bytes.Buffer
+	if error := mesh.WriteSTL(&stl); error != nil {
+		t.Fatalf("Unable to write STL: %v", error)
+	}
+	data := stl.Bytes()
+	if count := binary.LittleEndian.Uint32(data[80:84]); int(count) != len(mesh.Triangles) {
+		t.Errorf("STL holds %d triangles, expected %d", count, len(mesh.Triangles))
+	}
+	if len(data) != 84+50*len(mesh.Triangles) {
+		t.Errorf("STL is %d bytes long", len(data))
+	}
+	var obj bytes.Buffer
+	if error := mesh.WriteOBJ(&obj); error != nil {
+		t.Fatalf("Unable to write OBJ: %v", error)
+	}
+	text := obj.String()
+	if vertices := strings.Count(text, "\nv "); vertices != len(mesh.Vertices) {
+		t.Errorf("OBJ holds %d vertices, expected %d", vertices, len(mesh.Vertices))
+	}
+	if faces := strings.Count(text, "\nf "); faces != len(mesh.Triangles) {
+		t.Errorf("OBJ holds %d faces, expected %d", faces, len(mesh.Triangles))
+	}
+}
+
+func TestInvalidMeshStyle(t *testing.T) {
+	style := DefaultMeshStyle
+	style.WallThickness = style.CellSize
+	if _, error := style.Mesh(generator.Generate(2, 2, rand.New(rand.NewSource(0)))); error == nil {
+		t.Errorf("Mesh was built with walls as thick as the cells")
+	}
+}
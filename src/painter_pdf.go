@@ -0,0 +1,287 @@
+package painter
+
+import (
+	"board"
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// PageSize is the size of a page in PostScript points, 1/72 of an inch.
+type PageSize struct {
+	Width, Height float64
+}
+
+var (
+	A4     = PageSize{595.28, 841.89}
+	Letter = PageSize{612, 792}
+)
+
+var pageSizes map[string]PageSize = map[string]PageSize{
+	"a4":     A4,
+	"letter": Letter,
+}
+
+func LookupPageSize(name string) (PageSize, os.Error) {
+	size, ok := pageSizes[strings.ToLower(name)]
+	if !ok {
+		return size, os.NewError("Unknown page size " + name)
+	}
+	return size, nil
+}
+
+// Millimetres converts a length in millimetres to points.
+func Millimetres(mm float64) float64 {
+	return mm * 72 / 25.4
+}
+
+// Puzzle is a single maze of a book, captioned with its title, the seed it
+// was generated from and its size.
+type Puzzle struct {
+	Board board.Board
+	Title string
+	Seed  int64
+}
+
+// Book lays out puzzles on the pages of a PDF document, PerPage puzzles on
+// each page. If Answers is set, the puzzles are repeated with their solutions
+// on separate pages at the end of the book. The mazes are drawn in the
+// colours of the style, with its margin, path width, caps and markers, scaled
+// to fit; a nil style stands for DefaultStyle. The alpha of the colours is
+// dropped, as PDF pages are drawn opaque.
+type Book struct {
+	Page    PageSize
+	Margin  float64
+	PerPage int
+	Answers bool
+	Style   *Style
+}
+
+const (
+	titleFontSize   = 12
+	captionFontSize = 9
+	captionHeight   = titleFontSize + captionFontSize + 8
+	slotSpacing     = 18
+)
+
+func (self Book) Write(w io.Writer, puzzles []Puzzle) os.Error {
+	perPage := self.PerPage
+	if perPage < 1 {
+		perPage = 1
+	}
+	pages := make([][]byte, 0)
+	passes := []bool{false}
+	if self.Answers {
+		passes = append(passes, true)
+	}
+	for _, answers := range passes {
+		for first := 0; first < len(puzzles); first += perPage {
+			last := first + perPage
+			if last > len(puzzles) {
+				last = len(puzzles)
+			}
+			content, error := self.layOut(puzzles[first:last], perPage, answers)
+			if error != nil {
+				return error
+			}
+			pages = append(pages, content)
+		}
+	}
+	return writePDF(w, self.Page, pages)
+}
+
+// layOut draws the puzzles of a single page, arranged in a grid of slots.
+func (self Book) layOut(puzzles []Puzzle, perPage int, answers bool) ([]byte, os.Error) {
+	rows := int(math.Ceil(math.Sqrt(float64(perPage))))
+	columns := (perPage + rows - 1) / rows
+	slotWidth := (self.Page.Width - 2*self.Margin -
+		float64(columns-1)*slotSpacing) / float64(columns)
+	slotHeight := (self.Page.Height - 2*self.Margin -
+		float64(rows-1)*slotSpacing) / float64(rows)
+
+	style := DefaultStyle
+	if self.Style != nil {
+		style = *self.Style
+	}
+	var content bytes.Buffer
+	for i, puzzle := range puzzles {
+		left := self.Margin + float64(i%columns)*(slotWidth+slotSpacing)
+		top := self.Page.Height - self.Margin -
+			float64(i/columns)*(slotHeight+slotSpacing)
+		title := puzzle.Title
+		if answers {
+			title = "Solution: " + title
+		}
+		b := puzzle.Board
+		fmt.Fprintf(&content, "BT /F1 %d Tf %.2f %.2f Td (%s) Tj ET\n",
+			titleFontSize, left, top-titleFontSize, pdfString(title))
+		fmt.Fprintf(&content, "BT /F1 %d Tf %.2f %.2f Td (%s) Tj ET\n",
+			captionFontSize, left, top-titleFontSize-captionFontSize-4,
+			pdfString(fmt.Sprintf("Seed %d, %dx%d", puzzle.Seed, b.Width(), b.Height())))
+
+		// The maze with the margin of the style around it, measured in
+		// fields, is scaled to fit below the caption and centred in the slot.
+		margin := float64(style.Margin) / float64(style.CellSize)
+		cellSize := math.Min(slotWidth/(float64(b.Width())+2*margin),
+			(slotHeight-captionHeight)/(float64(b.Height())+2*margin))
+		mazeLeft := left + (slotWidth-cellSize*float64(b.Width()))/2
+		mazeTop := top - captionHeight - margin*cellSize
+		point := func(x, y float64) (float64, float64) {
+			return mazeLeft + x*cellSize, mazeTop - y*cellSize
+		}
+		if style.BoardColor.A != 0 {
+			x, y := point(-margin, float64(b.Height())+margin)
+			fmt.Fprintf(&content, "%s rg %.2f %.2f %.2f %.2f re f\n",
+				pdfColor(style.BoardColor), x, y,
+				(float64(b.Width())+2*margin)*cellSize, (float64(b.Height())+2*margin)*cellSize)
+		}
+
+		if answers {
+			path, error := b.Solve()
+			if error != nil {
+				return nil, error
+			}
+			fmt.Fprintf(&content, "%s RG %.2f w 1 J 1 j\n", pdfColor(style.PathColor),
+				cellSize*float64(style.PathWidth)/float64(style.CellSize))
+			for j, p := range path {
+				x, y := point(float64(p.X)+0.5, float64(p.Y)+0.5)
+				operator := "l"
+				if j == 0 {
+					operator = "m"
+				}
+				fmt.Fprintf(&content, "%.2f %.2f %s\n", x, y, operator)
+			}
+			fmt.Fprintf(&content, "S\n")
+			if style.Markers {
+				size := cellSize / 2
+				for _, marker := range []struct {
+					Field image.Point
+					Color image.RGBAColor
+				}{{*b.Entrance(), style.EntranceColor}, {*b.Exit(), style.ExitColor}} {
+					x, y := point(float64(marker.Field.X)+0.25, float64(marker.Field.Y)+0.75)
+					fmt.Fprintf(&content, "%s rg %.2f %.2f %.2f %.2f re f\n",
+						pdfColor(marker.Color), x, y, size, size)
+				}
+			}
+		}
+
+		caps := "2 J 0 j"
+		if style.RoundCaps {
+			caps = "1 J 1 j"
+		}
+		fmt.Fprintf(&content, "%s RG %.2f w %s\n",
+			pdfColor(style.WallColor), math.Min(cellSize/8, 2), caps)
+		for _, wall := range Walls(b) {
+			x1, y1 := point(float64(wall.From.X), float64(wall.From.Y))
+			x2, y2 := point(float64(wall.To.X), float64(wall.To.Y))
+			fmt.Fprintf(&content, "%.2f %.2f m %.2f %.2f l\n", x1, y1, x2, y2)
+		}
+		fmt.Fprintf(&content, "S\n")
+	}
+	return content.Bytes(), nil
+}
+
+func pdfString(text string) string {
+	text = strings.Replace(text, "\\", "\\\\", -1)
+	text = strings.Replace(text, "(", "\\(", -1)
+	return strings.Replace(text, ")", "\\)", -1)
+}
+
+func pdfColor(color image.RGBAColor) string {
+	return fmt.Sprintf("%.3f %.3f %.3f",
+		float64(color.R)/0xff, float64(color.G)/0xff, float64(color.B)/0xff)
+}
+
+// pdfWriter keeps track of the byte offsets of the objects, which are listed
+// in the cross-reference table at the end of the document.
+type pdfWriter struct {
+	w       *bufio.Writer
+	offset  int
+	offsets []int
+	error   os.Error
+}
+
+func (self *pdfWriter) printf(format string, args ...interface{}) {
+	if self.error != nil {
+		return
+	}
+	var n int
+	n, self.error = fmt.Fprintf(self.w, format, args...)
+	self.offset += n
+}
+
+func (self *pdfWriter) write(data []byte) {
+	if self.error != nil {
+		return
+	}
+	var n int
+	n, self.error = self.w.Write(data)
+	self.offset += n
+}
+
+func (self *pdfWriter) beginObject(id int) {
+	self.offsets[id-1] = self.offset
+	self.printf("%d 0 obj\n", id)
+}
+
+// writePDF writes a document with the given page contents. The catalog, page
+// tree and font are objects 1 to 3, followed by a page and its content stream
+// for every page.
+func writePDF(w io.Writer, size PageSize, pages [][]byte) os.Error {
+	const firstPage = 4
+	self := &pdfWriter{w: bufio.NewWriter(w), offsets: make([]int, 3+2*len(pages))}
+	self.printf("%%PDF-1.4\n")
+
+	self.beginObject(1)
+	self.printf("<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	self.beginObject(2)
+	self.printf("<< /Type /Pages /Count %d /MediaBox [0 0 %.2f %.2f] /Kids [",
+		len(pages), size.Width, size.Height)
+	for i := range pages {
+		self.printf(" %d 0 R", firstPage+2*i)
+	}
+	self.printf(" ] >>\nendobj\n")
+	self.beginObject(3)
+	self.printf("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica " +
+		"/Encoding /WinAnsiEncoding >>\nendobj\n")
+
+	for i, content := range pages {
+		id := firstPage + 2*i
+		self.beginObject(id)
+		self.printf("<< /Type /Page /Parent 2 0 R "+
+			"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>\nendobj\n",
+			id+1)
+
+		var compressed bytes.Buffer
+		compressor, error := zlib.NewWriterLevel(&compressed, zlib.BestCompression)
+		if error != nil {
+			return error
+		}
+		compressor.Write(content)
+		if error = compressor.Close(); error != nil {
+			return error
+		}
+		self.beginObject(id + 1)
+		self.printf("<< /Length %d /Filter /FlateDecode >>\nstream\n", compressed.Len())
+		self.write(compressed.Bytes())
+		self.printf("\nendstream\nendobj\n")
+	}
+
+	xref := self.offset
+	self.printf("xref\n0 %d\n0000000000 65535 f \n", len(self.offsets)+1)
+	for _, offset := range self.offsets {
+		self.printf("%010d 00000 n \n", offset)
+	}
+	self.printf("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(self.offsets)+1, xref)
+	if self.error != nil {
+		return self.error
+	}
+	return self.w.Flush()
+}
@@ -0,0 +1,96 @@
+package painter
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWritingBook(t *testing.T) {
+	puzzles := []Puzzle{
+		{smallBoard(), "First (easy)", 1},
+		{smallBoard(), "Second", 2},
+		{smallBoard(), "Third", 3},
+	}
+	book := Book{Page: A4, Margin: Millimetres(15), PerPage: 2, Answers: true}
+	var buf bytes.Buffer
+	if error := book.Write(&buf, puzzles); error != nil {
+		t.Fatalf("Unable to write the book: %v", error)
+	}
+	pdf := buf.String()
+	if !strings.HasPrefix(pdf, "%PDF-1.4\n") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatalf("Book is not a PDF document:\n%s", pdf)
+	}
+	if !strings.Contains(pdf, "/Count 4 ") {
+		t.Errorf("Book should have two puzzle and two answer pages")
+	}
+
+	// Every object listed in the cross-reference table has to start at the
+	// recorded offset.
+	trailer := pdf[strings.LastIndex(pdf, "startxref\n")+len("startxref\n"):]
+	xref, error := strconv.Atoi(trailer[:strings.Index(trailer, "\n")])
+	if error != nil || !strings.HasPrefix(pdf[xref:], "xref\n") {
+		t.Fatalf("Invalid cross-reference table offset %q", trailer)
+	}
+	lines := strings.Split(pdf[xref:], "\n")
+	for id := 1; id < 1+3+2*4; id++ {
+		offset, _ := strconv.Atoi(lines[2+id][:10])
+		if object := fmt.Sprintf("%d 0 obj\n", id); !strings.HasPrefix(pdf[offset:], object) {
+			t.Errorf("Object %d not found at offset %d", id, offset)
+		}
+	}
+
+	// The last page holds the answer to the third puzzle.
+	stream := pdf[strings.LastIndex(pdf, ">>\nstream\n")+len(">>\nstream\n"):]
+	reader, error := zlib.NewReader(strings.NewReader(stream))
+	if error != nil {
+		t.Fatalf("Unable to decompress the last page: %v", error)
+	}
+	content, _ := ioutil.ReadAll(reader)
+	if !strings.Contains(string(content), "(Solution: Third)") {
+		t.Errorf("Last page is not the answer to the third puzzle:\n%s", content)
+	}
+	if !strings.Contains(string(content), "(Seed 3, 2x2)") {
+		t.Errorf("Caption of the third puzzle is missing:\n%s", content)
+	}
+}
+
+func TestWritingBookWithStyle(t *testing.T) {
+	style := DefaultStyle
+	if error := style.Parse(testStyle); error != nil {
+		t.Fatalf("Unable to parse the style: %v", error)
+	}
+	book := Book{Page: A4, PerPage: 1, Answers: true, Style: &style}
+	var buf bytes.Buffer
+	if error := book.Write(&buf, []Puzzle{{smallBoard(), "Maze", 1}}); error != nil {
+		t.Fatalf("Unable to write the book: %v", error)
+	}
+	pdf := buf.String()
+	stream := pdf[strings.LastIndex(pdf, ">>\nstream\n")+len(">>\nstream\n"):]
+	reader, error := zlib.NewReader(strings.NewReader(stream))
+	if error != nil {
+		t.Fatalf("Unable to decompress the answer page: %v", error)
+	}
+	content, _ := ioutil.ReadAll(reader)
+	for _, expected := range []string{
+		"1.000 1.000 0.000 rg", "0.200 0.400 0.600 RG",
+		// Two fields and the margins of a quarter field around them fill
+		// the width of the page, and the path is a quarter field wide.
+		"1.000 0.000 1.000 RG 59.53 w",
+		"0.000 0.000 1.000 rg", "1 J 1 j",
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Answer page doesn't contain %q:\n%s", expected, content)
+		}
+	}
+}
+
+func TestEscapingPDFStrings(t *testing.T) {
+	if escaped := pdfString(`a\(b)`); escaped != `a\\\(b\)` {
+		t.Errorf("Got %s", escaped)
+	}
+}
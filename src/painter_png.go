@@ -0,0 +1,157 @@
+package painter
+
+import (
+	"bufio"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"io"
+	"os"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// pngWriter encodes an RGBA image one scanline at a time, so that images
+// much larger than the available memory can be written.
+type pngWriter struct {
+	w          io.Writer
+	data       *bufio.Writer
+	compressor io.WriteCloser
+	width      int
+	row        []uint8
+	error      os.Error
+}
+
+func newPNGWriter(w io.Writer, width, height int) (*pngWriter, os.Error) {
+	self := &pngWriter{w: w, width: width, row: make([]uint8, 1+4*width)}
+	if _, error := w.Write(pngSignature); error != nil {
+		return nil, error
+	}
+	header := make([]byte, 13)
+	binary.BigEndian.PutUint32(header[0:4], uint32(width))
+	binary.BigEndian.PutUint32(header[4:8], uint32(height))
+	header[8] = 8 // Bit depth
+	header[9] = 6 // Color type: RGBA
+	if error := self.writeChunk("IHDR", header); error != nil {
+		return nil, error
+	}
+	self.data = bufio.NewWriter(idatWriter{self})
+	compressor, error := zlib.NewWriterLevel(self.data, zlib.DefaultCompression)
+	if error != nil {
+		return nil, error
+	}
+	self.compressor = compressor
+	return self, nil
+}
+
+func (self *pngWriter) writeChunk(name string, data []byte) os.Error {
+	if self.error != nil {
+		return self.error
+	}
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header[0:4], uint32(len(data)))
+	copy(header[4:8], name)
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:8])
+	crc.Write(data)
+	footer := make([]byte, 4)
+	binary.BigEndian.PutUint32(footer, crc.Sum32())
+	for _, part := range [][]byte{header, data, footer} {
+		if _, self.error = self.w.Write(part); self.error != nil {
+			return self.error
+		}
+	}
+	return nil
+}
+
+// WriteRow writes a scanline given as consecutive R, G, B, A bytes, with the
+// colour components multiplied by the alpha like in image.RGBA. PNG stores
+// them divided by the alpha again, the same way as png.Encode does.
+func (self *pngWriter) WriteRow(pixels []uint8) os.Error {
+	if self.error != nil {
+		return self.error
+	}
+	self.row[0] = 0 // Filter type: none
+	for i := 0; i < 4*self.width; i += 4 {
+		row := self.row[1+i : 5+i]
+		copy(row, pixels[i:i+4])
+		if alpha := uint32(row[3]) * 0x101; alpha != 0 && alpha != 0xffff {
+			for c := 0; c < 3; c++ {
+				row[c] = uint8(uint32(row[c]) * 0x101 * 0xffff / alpha >> 8)
+			}
+		}
+	}
+	_, self.error = self.compressor.Write(self.row)
+	return self.error
+}
+
+func (self *pngWriter) Close() os.Error {
+	if self.error != nil {
+		return self.error
+	}
+	if self.error = self.compressor.Close(); self.error != nil {
+		return self.error
+	}
+	if self.error = self.data.Flush(); self.error != nil {
+		return self.error
+	}
+	return self.writeChunk("IEND", nil)
+}
+
+type idatWriter struct {
+	png *pngWriter
+}
+
+func (self idatWriter) Write(data []byte) (int, os.Error) {
+	if error := self.png.writeChunk("IDAT", data); error != nil {
+		return 0, error
+	}
+	return len(data), nil
+}
+
+// pngSequence writes each frame of an animation to a PNG file of its own,
+// named by formatting the frame number with the pattern.
+type pngSequence struct {
+	pattern       string
+	width, height int
+	palette       []image.RGBAColor
+	frame         int
+	error         os.Error
+}
+
+func (self *pngSequence) WriteFrame(pixels []uint8, delay int) os.Error {
+	if self.error != nil {
+		return self.error
+	}
+	self.frame++
+	self.error = self.writeFile(fmt.Sprintf(self.pattern, self.frame), pixels)
+	return self.error
+}
+
+func (self *pngSequence) writeFile(fileName string, pixels []uint8) os.Error {
+	file, error := os.Create(fileName)
+	if error != nil {
+		return error
+	}
+	defer file.Close()
+	out, error := newPNGWriter(file, self.width, self.height)
+	if error != nil {
+		return error
+	}
+	line := make([]uint8, 4*self.width)
+	for y := 0; y < self.height; y++ {
+		for x, index := range pixels[y*self.width : (y+1)*self.width] {
+			fillLine(line, x, x+1, self.palette[index])
+		}
+		if error = out.WriteRow(line); error != nil {
+			return error
+		}
+	}
+	return out.Close()
+}
+
+func (self *pngSequence) Close() os.Error {
+	return self.error
+}
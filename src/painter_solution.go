@@ -0,0 +1,48 @@
+package painter
+
+import (
+	"board"
+	"image"
+)
+
+// fieldCenter returns the pixel in the middle of the field's interior.
+func fieldCenter(p image.Point, cellSize, wallThickness int) image.Point {
+	offset := wallThickness + (cellSize-wallThickness)/2
+	return image.Pt(p.X*cellSize+offset, p.Y*cellSize+offset)
+}
+
+// drawPath draws the path as a continuous line of the given width through
+// the centres of its fields.
+func drawPath(img *image.RGBA, origin image.Point, path board.Path,
+	cellSize, wallThickness int, color image.RGBAColor, width int) {
+	for i := range path {
+		from := fieldCenter(path[i], cellSize, wallThickness)
+		to := from
+		if i+1 < len(path) {
+			to = fieldCenter(path[i+1], cellSize, wallThickness)
+		}
+		segment := image.Rectangle{from, to}.Canon().Add(origin)
+		segment.Min = segment.Min.Sub(image.Pt(width/2, width/2))
+		segment.Max = segment.Max.Add(image.Pt(width-width/2, width-width/2))
+		DrawRect(img, segment, color)
+	}
+}
+
+// drawMarkers marks the entrance and the exit of the board with squares of
+// half the size of a field.
+func drawMarkers(img *image.RGBA, origin image.Point, b board.Board,
+	cellSize, wallThickness int, entrance, exit image.RGBAColor) {
+	size := markerSize(cellSize, wallThickness)
+	for _, marker := range []struct {
+		Field image.Point
+		Color image.RGBAColor
+	}{{*b.Entrance(), entrance}, {*b.Exit(), exit}} {
+		center := fieldCenter(marker.Field, cellSize, wallThickness).Add(origin)
+		min := center.Sub(image.Pt(size/2, size/2))
+		DrawRect(img, image.Rectangle{min, min.Add(image.Pt(size, size))}, marker.Color)
+	}
+}
+
+func markerSize(cellSize, wallThickness int) int {
+	return (cellSize - wallThickness + 1) / 2
+}
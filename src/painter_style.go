@@ -0,0 +1,232 @@
+package painter
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+	"json"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+var (
+	entranceColor = image.RGBAColor{0, 0, 0xff, 0xff}
+	exitColor     = image.RGBAColor{0xff, 0, 0, 0xff}
+)
+
+// Style describes how boards are rendered. Sizes are given in pixels, or in
+// user units of the SVG pictures. The entrance and exit are only marked when
+// a path is drawn.
+type Style struct {
+	WallColor, BoardColor, PathColor image.RGBAColor
+	EntranceColor, ExitColor         image.RGBAColor
+	CellSize, WallThickness, Margin  int
+	PathWidth                        int
+	Markers                          bool
+	RoundCaps                        bool
+}
+
+var DefaultStyle = Style{
+	WallColor:     wallColor,
+	BoardColor:    boardColor,
+	PathColor:     pathColor,
+	EntranceColor: entranceColor,
+	ExitColor:     exitColor,
+	CellSize:      10,
+	WallThickness: 2,
+	PathWidth:     3,
+	Markers:       true,
+}
+
+// ParseColor parses a colour given in hexadecimal, such as "#00ff00", or
+// "#00ff0080" for a half transparent one.
+func ParseColor(spec string) (image.RGBAColor, os.Error) {
+	hex := spec
+	if strings.HasPrefix(hex, "#") {
+		hex = hex[1:]
+	}
+	value, error := strconv.Btoui64(hex, 16)
+	if error != nil || (len(hex) != 6 && len(hex) != 8) {
+		return image.RGBAColor{}, os.NewError("Invalid colour " + spec)
+	}
+	alpha := uint64(0xff)
+	if len(hex) == 8 {
+		alpha = value & 0xff
+		value >>= 8
+	}
+	// Colours are stored with their components multiplied by the alpha.
+	premultiply := func(component uint64) uint8 {
+		return uint8((component & 0xff) * alpha / 0xff)
+	}
+	return image.RGBAColor{premultiply(value >> 16), premultiply(value >> 8),
+		premultiply(value), uint8(alpha)}, nil
+}
+
+// FormatColor formats a colour the way ParseColor parses it.
+func FormatColor(color image.RGBAColor) string {
+	if color.A == 0xff {
+		return fmt.Sprintf("#%02x%02x%02x", color.R, color.G, color.B)
+	}
+	unpremultiply := func(component uint8) uint32 {
+		if color.A == 0 {
+			return 0
+		}
+		return uint32(component) * 0xff / uint32(color.A)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", unpremultiply(color.R),
+		unpremultiply(color.G), unpremultiply(color.B), color.A)
+}
+
+// Set sets a single option of the style, such as "wall-color" to "#000000".
+// Underscores may be used instead of dashes in the names.
+func (self *Style) Set(name, value string) os.Error {
+	name = strings.Replace(strings.ToLower(strings.TrimSpace(name)), "_", "-", -1)
+	value = strings.TrimSpace(value)
+	colors := map[string]*image.RGBAColor{
+		"wall-color":     &self.WallColor,
+		"board-color":    &self.BoardColor,
+		"path-color":     &self.PathColor,
+		"entrance-color": &self.EntranceColor,
+		"exit-color":     &self.ExitColor,
+	}
+	sizes := map[string]*int{
+		"cell-size":      &self.CellSize,
+		"wall-thickness": &self.WallThickness,
+		"margin":         &self.Margin,
+		"path-width":     &self.PathWidth,
+	}
+	var error os.Error
+	if color, ok := colors[name]; ok {
+		*color, error = ParseColor(value)
+		return error
+	}
+	if size, ok := sizes[name]; ok {
+		if *size, error = strconv.Atoi(value); error != nil || *size < 0 {
+			return fmt.Errorf("Invalid %s: %s", name, value)
+		}
+		return nil
+	}
+	switch name {
+	case "markers":
+		if self.Markers, error = strconv.Atob(value); error != nil {
+			return fmt.Errorf("Invalid %s: %s", name, value)
+		}
+	case "caps":
+		if value != "round" && value != "square" {
+			return fmt.Errorf("Invalid %s: %s, expected round or square", name, value)
+		}
+		self.RoundCaps = value == "round"
+	default:
+		return os.NewError("Unknown style option " + name)
+	}
+	return nil
+}
+
+// Parse sets the options of a comma-separated list, such as
+// "cell-size=20,caps=round".
+func (self *Style) Parse(spec string) os.Error {
+	for _, option := range strings.Split(spec, ",") {
+		if strings.TrimSpace(option) == "" {
+			continue
+		}
+		equals := strings.Index(option, "=")
+		if equals < 0 {
+			return os.NewError("Invalid style option " + option)
+		}
+		if error := self.Set(option[:equals], option[equals+1:]); error != nil {
+			return error
+		}
+	}
+	return self.Validate()
+}
+
+// Validate checks that the fields are larger than their walls.
+func (self *Style) Validate() os.Error {
+	if self.CellSize <= self.WallThickness {
+		return fmt.Errorf("Cell size %d has to be larger than the wall thickness %d",
+			self.CellSize, self.WallThickness)
+	}
+	return nil
+}
+
+// ReadJSON sets the options of a JSON object, such as
+// {"cell-size": 20, "caps": "round"}.
+func (self *Style) ReadJSON(r io.Reader) os.Error {
+	var options map[string]interface{}
+	if error := json.NewDecoder(r).Decode(&options); error != nil {
+		return error
+	}
+	for name, value := range options {
+		if error := self.Set(name, fmt.Sprint(value)); error != nil {
+			return error
+		}
+	}
+	return self.Validate()
+}
+
+// ReadTOML sets the options of a flat TOML document, made of lines such as
+// cell_size = 20 or caps = "round". Tables are not supported.
+func (self *Style) ReadTOML(r io.Reader) os.Error {
+	reader := bufio.NewReader(r)
+	for lineNumber := 1; ; lineNumber++ {
+		line, error := reader.ReadString('\n')
+		if error != nil && error != os.EOF {
+			return error
+		}
+		if line = strings.TrimSpace(stripComment(line)); line != "" {
+			equals := strings.Index(line, "=")
+			if equals < 0 {
+				return fmt.Errorf("Line %d: expected name = value", lineNumber)
+			}
+			value := strings.TrimSpace(line[equals+1:])
+			if unquoted, quoteError := strconv.Unquote(value); quoteError == nil {
+				value = unquoted
+			}
+			if setError := self.Set(line[:equals], value); setError != nil {
+				return fmt.Errorf("Line %d: %v", lineNumber, setError)
+			}
+		}
+		if error == os.EOF {
+			break
+		}
+	}
+	return self.Validate()
+}
+
+// stripComment removes a comment starting with a hash sign outside of a
+// quoted string.
+func stripComment(line string) string {
+	quoted := false
+	for i, c := range line {
+		switch {
+		case c == '"' && (i == 0 || line[i-1] != '\\'):
+			quoted = !quoted
+		case c == '#' && !quoted:
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// LoadStyle reads a theme file on top of the default style. The format is
+// chosen by the extension of the file, .json or .toml.
+func LoadStyle(fileName string) (Style, os.Error) {
+	style := DefaultStyle
+	file, error := os.Open(fileName)
+	if error != nil {
+		return style, error
+	}
+	defer file.Close()
+	switch strings.ToLower(path.Ext(fileName)) {
+	case ".json":
+		error = style.ReadJSON(file)
+	case ".toml":
+		error = style.ReadTOML(file)
+	default:
+		error = os.NewError("Unknown theme format " + fileName)
+	}
+	return style, error
+}
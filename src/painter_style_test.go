@@ -0,0 +1,116 @@
+package painter
+
+import (
+	"bytes"
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestParsingTransparentColors(t *testing.T) {
+	parsed, error := ParseColor("#ff000080")
+	if error != nil || parsed.R != 0x80 || parsed.G != 0 || parsed.A != 0x80 {
+		t.Errorf("Parsed %v, %v", parsed, error)
+	}
+	for _, spec := range []string{"#12ab0f", "#ff000080", "#00000000"} {
+		parsed, _ := ParseColor(spec)
+		if formatted := FormatColor(parsed); formatted != spec {
+			t.Errorf("Colour %s was formatted as %s", spec, formatted)
+		}
+	}
+}
+
+func TestParsingStyles(t *testing.T) {
+	style := DefaultStyle
+	if error := style.Parse("cell-size=20, wall_thickness=4,caps=round,markers=false"); error != nil {
+		t.Fatalf("Unable to parse the style: %v", error)
+	}
+	if style.CellSize != 20 || style.WallThickness != 4 || !style.RoundCaps || style.Markers {
+		t.Errorf("Parsed style is %+v", style)
+	}
+	for _, spec := range []string{
+		"cell-size", "cell-size=-1", "caps=pointy", "wall-color=black",
+		"no-such-option=1", "cell-size=4,wall-thickness=4",
+	} {
+		style := DefaultStyle
+		if error := style.Parse(spec); error == nil {
+			t.Errorf("Invalid style %q was parsed", spec)
+		}
+	}
+}
+
+func TestReadingThemes(t *testing.T) {
+	fromJSON, fromTOML := DefaultStyle, DefaultStyle
+	error := fromJSON.ReadJSON(strings.NewReader(
+		`{"wall-color": "#336699", "margin": 8, "markers": false, "caps": "round"}`))
+	if error != nil {
+		t.Fatalf("Unable to read the JSON theme: %v", error)
+	}
+	error = fromTOML.ReadTOML(strings.NewReader(`# A theme
+wall_color = "#336699" # With a comment
+margin = 8
+
+markers = false
+caps = "round"`))
+	if error != nil {
+		t.Fatalf("Unable to read the TOML theme: %v", error)
+	}
+	for _, style := range []Style{fromJSON, fromTOML} {
+		if FormatColor(style.WallColor) != "#336699" || style.Margin != 8 ||
+			style.Markers || !style.RoundCaps {
+			t.Errorf("Read style is %+v", style)
+		}
+	}
+	if error := fromTOML.ReadTOML(strings.NewReader("margin\n")); error == nil ||
+		!strings.Contains(error.String(), "Line 1") {
+		t.Errorf("Expected an error on line 1, got %v", error)
+	}
+}
+
+func TestPaintingWithStyle(t *testing.T) {
+	style := DefaultStyle
+	if error := style.Parse("cell-size=12,wall-thickness=4,margin=3,caps=round,board-color=#ffff00"); error != nil {
+		t.Fatalf("Unable to parse the style: %v", error)
+	}
+	img := style.Paint(smallBoard(), nil, nil)
+	if !img.Bounds().Eq(image.Rect(0, 0, 34, 34)) {
+		t.Fatalf("Image bounds are %v", img.Bounds())
+	}
+	isColor := func(x, y int, expected image.RGBAColor) bool {
+		r, g, b, _ := img.At(x, y).RGBA()
+		return r>>8 == uint32(expected.R) && g>>8 == uint32(expected.G) &&
+			b>>8 == uint32(expected.B)
+	}
+	// The margin, the rounded outer corner of the post at (0, 2) and the
+	// middle of the western wall.
+	for _, p := range []image.Point{{0, 0}, {33, 33}, {3, 27}} {
+		if !isColor(p.X, p.Y, style.BoardColor) {
+			t.Errorf("Pixel %v is not painted with the board colour", p)
+		}
+	}
+	for _, p := range []image.Point{{4, 28}, {3, 15}} {
+		if !isColor(p.X, p.Y, style.WallColor) {
+			t.Errorf("Pixel %v is not painted with the wall colour", p)
+		}
+	}
+}
+
+func TestPaintingSVGWithStyle(t *testing.T) {
+	style := DefaultStyle
+	if error := style.Parse("margin=5,caps=round,wall-color=#00000080"); error != nil {
+		t.Fatalf("Unable to parse the style: %v", error)
+	}
+	var buf bytes.Buffer
+	if error := style.PaintSVG(&buf, smallBoard(), nil); error != nil {
+		t.Fatalf("Unable to paint the board: %v", error)
+	}
+	svg := buf.String()
+	for _, expected := range []string{
+		`width="32" height="32"`, `translate(5 5)`, `stroke-linecap="round"`,
+		`stroke="#000000" stroke-opacity="0.502"`,
+	} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("Picture doesn't contain %s:\n%s", expected, svg)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package painter
+
+import (
+	"board"
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+	"os"
+)
+
+// Wall is a straight wall between two corners of the board, given in field
+// coordinates, so that the corner (x, y) is the top left corner of field
+// (x, y).
+type Wall struct {
+	From, To image.Point
+}
+
+// Walls returns the walls of the board, merging the walls of neighbouring
+// fields that lie on one line into a single long wall. Horizontal walls come
+// first, from top to bottom, followed by vertical ones, from left to right.
+func Walls(b board.Board) []Wall {
+	width, height := b.Width(), b.Height()
+	walls := make([]Wall, 0)
+	for y := 0; y <= height; y++ {
+		start := -1
+		for x := 0; x <= width; x++ {
+			closed := x < width && !hasPassage(b, x, y, board.N)
+			if closed && start < 0 {
+				start = x
+			} else if !closed && start >= 0 {
+				walls = append(walls, Wall{image.Pt(start, y), image.Pt(x, y)})
+				start = -1
+			}
+		}
+	}
+	for x := 0; x <= width; x++ {
+		start := -1
+		for y := 0; y <= height; y++ {
+			closed := y < height && !hasPassage(b, x, y, board.W)
+			if closed && start < 0 {
+				start = y
+			} else if !closed && start >= 0 {
+				walls = append(walls, Wall{image.Pt(x, start), image.Pt(x, y)})
+				start = -1
+			}
+		}
+	}
+	return walls
+}
+
+// hasPassage tells whether there is a passage through the northern or western
+// side of the field. Fields past the last row or column stand for the southern
+// or eastern border of the board, like in Paint.
+func hasPassage(b board.Board, x, y int, side board.Direction) bool {
+	switch {
+	case side == board.N && y == b.Height():
+		return b.At(x, y-1).Direction()&board.S != 0
+	case side == board.W && x == b.Width():
+		return b.At(x-1, y).Direction()&board.E != 0
+	}
+	return b.At(x, y).Direction()&side != 0
+}
+
+// PaintSVG renders the board like Paint does, but as a scalable vector
+// picture. If the path is not nil, it is drawn through the centres of its
+// fields.
+func (self Style) PaintSVG(w io.Writer, b board.Board, path board.Path) os.Error {
+	out := bufio.NewWriter(w)
+	cellSize := self.CellSize
+	width := b.Width()*cellSize + self.WallThickness + 2*self.Margin
+	height := b.Height()*cellSize + self.WallThickness + 2*self.Margin
+	offset := float64(self.WallThickness) / 2
+	center := func(p image.Point) (float64, float64) {
+		c := fieldCenter(p, cellSize, self.WallThickness)
+		return float64(c.X), float64(c.Y)
+	}
+	fmt.Fprintf(out, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(out, "<svg xmlns=\"http://www.w3.org/2000/svg\" "+
+		"width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		width, height, width, height)
+	fmt.Fprintf(out, "<rect width=\"%d\" height=\"%d\" %s/>\n",
+		width, height, svgPaint("fill", self.BoardColor))
+	fmt.Fprintf(out, "<g transform=\"translate(%d %d)\">\n", self.Margin, self.Margin)
+
+	if len(path) > 0 {
+		fmt.Fprintf(out, "<polyline fill=\"none\" %s "+
+			"stroke-width=\"%d\" stroke-linecap=\"round\" "+
+			"stroke-linejoin=\"round\" points=\"",
+			svgPaint("stroke", self.PathColor), self.PathWidth)
+		for i, p := range path {
+			if i > 0 {
+				fmt.Fprint(out, " ")
+			}
+			x, y := center(p)
+			fmt.Fprintf(out, "%g,%g", x, y)
+		}
+		fmt.Fprintf(out, "\"/>\n")
+		if self.Markers {
+			size := markerSize(cellSize, self.WallThickness)
+			for _, marker := range []struct {
+				Field image.Point
+				Color image.RGBAColor
+			}{{*b.Entrance(), self.EntranceColor}, {*b.Exit(), self.ExitColor}} {
+				x, y := center(marker.Field)
+				fmt.Fprintf(out, "<rect x=\"%g\" y=\"%g\" width=\"%d\" height=\"%d\" %s/>\n",
+					x-float64(size/2), y-float64(size/2), size, size,
+					svgPaint("fill", marker.Color))
+			}
+		}
+	}
+
+	caps := "square"
+	if self.RoundCaps {
+		caps = "round"
+	}
+	fmt.Fprintf(out, "<g %s stroke-width=\"%d\" stroke-linecap=\"%s\">\n",
+		svgPaint("stroke", self.WallColor), self.WallThickness, caps)
+	for _, wall := range Walls(b) {
+		fmt.Fprintf(out, "<line x1=\"%g\" y1=\"%g\" x2=\"%g\" y2=\"%g\"/>\n",
+			float64(wall.From.X*cellSize)+offset,
+			float64(wall.From.Y*cellSize)+offset,
+			float64(wall.To.X*cellSize)+offset,
+			float64(wall.To.Y*cellSize)+offset)
+	}
+	fmt.Fprintf(out, "</g>\n</g>\n</svg>\n")
+	return out.Flush()
+}
+
+// svgPaint returns the attribute setting the colour, with the opacity in a
+// separate attribute if the colour is transparent.
+func svgPaint(attribute string, color image.RGBAColor) string {
+	hex := FormatColor(color)
+	if color.A == 0xff {
+		return fmt.Sprintf("%s=\"%s\"", attribute, hex)
+	}
+	return fmt.Sprintf("%s=\"%s\" %s-opacity=\"%.3g\"", attribute, hex[:7],
+		attribute, float64(color.A)/0xff)
+}
@@ -0,0 +1,65 @@
+package painter
+
+import (
+	"board"
+	"bytes"
+	"image"
+	"strings"
+	"testing"
+)
+
+func smallBoard() board.Board {
+	//  _ ___
+	// |     |
+	// |  ___|
+	// |___  |
+	b := board.New(2, 2)
+	b.At(0, 0).AddDirection(board.N | board.E | board.S)
+	b.At(1, 0).AddDirection(board.W)
+	b.At(0, 1).AddDirection(board.N | board.E)
+	b.At(1, 1).AddDirection(board.W | board.S)
+	*b.Entrance() = image.Pt(0, 0)
+	*b.Exit() = image.Pt(1, 1)
+	return b
+}
+
+func TestWalls(t *testing.T) {
+	expected := []Wall{
+		{image.Pt(1, 0), image.Pt(2, 0)},
+		{image.Pt(1, 1), image.Pt(2, 1)},
+		{image.Pt(0, 2), image.Pt(1, 2)},
+		{image.Pt(0, 0), image.Pt(0, 2)},
+		{image.Pt(2, 0), image.Pt(2, 2)},
+	}
+	walls := Walls(smallBoard())
+	if len(walls) != len(expected) {
+		t.Fatalf("Got %d walls, expected %d: %v", len(walls), len(expected), walls)
+	}
+	for i, wall := range walls {
+		if !wall.From.Eq(expected[i].From) || !wall.To.Eq(expected[i].To) {
+			t.Errorf("Wall %d is %v, expected %v", i, wall, expected[i])
+		}
+	}
+}
+
+func TestPaintingSVG(t *testing.T) {
+	b := smallBoard()
+	path, error := b.Solve()
+	if error != nil {
+		t.Fatalf("Unable to solve the board: %v", error)
+	}
+	var buf bytes.Buffer
+	if error := DefaultStyle.PaintSVG(&buf, b, path); error != nil {
+		t.Fatalf("Unable to paint the board: %v", error)
+	}
+	svg := buf.String()
+	if !strings.Contains(svg, `width="22" height="22"`) {
+		t.Errorf("Unexpected picture size:\n%s", svg)
+	}
+	if lines := strings.Count(svg, "<line "); lines != 5 {
+		t.Errorf("Got %d lines, expected 5:\n%s", lines, svg)
+	}
+	if !strings.Contains(svg, `points="6,6 6,16 16,16"`) {
+		t.Errorf("Solution path is missing:\n%s", svg)
+	}
+}
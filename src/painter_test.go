@@ -0,0 +1,131 @@
+package painter
+
+import (
+	"board"
+	"bytes"
+	"generator"
+	"image"
+	"image/png"
+	"rand"
+	"testing"
+)
+
+func TestPaintingRows(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	b := generator.Generate(7, 4, rng)
+	const cellSize, wallThickness = 5, 2
+	var buf bytes.Buffer
+	style := DefaultStyle
+	style.CellSize, style.WallThickness = cellSize, wallThickness
+	if error := style.PaintRows(&buf, board.RowsOf(b)); error != nil {
+		t.Fatalf("Unable to paint rows: %v", error)
+	}
+	streamed, error := png.Decode(&buf)
+	if error != nil {
+		t.Fatalf("Unable to decode the streamed image: %v", error)
+	}
+	painted := Paint(b, nil, cellSize, wallThickness)
+	if !streamed.Bounds().Eq(painted.Bounds()) {
+		t.Fatalf("Streamed image bounds are %v, expected %v",
+			streamed.Bounds(), painted.Bounds())
+	}
+	bounds := painted.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r1, g1, b1, a1 := streamed.At(x, y).RGBA()
+			r2, g2, b2, a2 := painted.At(x, y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				t.Fatalf("Pixel %v differs from the painted image",
+					image.Pt(x, y))
+			}
+		}
+	}
+}
+
+// testStyle changes everything the renderers have to take from a style.
+const testStyle = "cell-size=12,wall-thickness=4,margin=3,caps=round," +
+	"board-color=#ffff00,wall-color=#336699,path-color=#ff00ff"
+
+// sameColors tells whether the images have the same size and colours,
+// ignoring the alpha channel.
+func sameColors(img1, img2 image.Image) bool {
+	if !img1.Bounds().Eq(img2.Bounds()) {
+		return false
+	}
+	bounds := img1.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r1, g1, b1, _ := img1.At(x, y).RGBA()
+			r2, g2, b2, _ := img2.At(x, y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestPaintingRowsWithStyle(t *testing.T) {
+	style := DefaultStyle
+	if error := style.Parse(testStyle); error != nil {
+		t.Fatalf("Unable to parse the style: %v", error)
+	}
+	for _, size := range []image.Point{{7, 4}, {1, 1}, {3, 1}} {
+		b := generator.Generate(size.X, size.Y, rand.New(rand.NewSource(0)))
+		var buf bytes.Buffer
+		if error := style.PaintRows(&buf, board.RowsOf(b)); error != nil {
+			t.Fatalf("Unable to paint rows: %v", error)
+		}
+		streamed, error := png.Decode(&buf)
+		if error != nil {
+			t.Fatalf("Unable to decode the streamed image: %v", error)
+		}
+		if !sameColors(streamed, style.Paint(b, nil, nil)) {
+			t.Errorf("Streamed image of the %v board differs from the painted image", size)
+		}
+	}
+}
+
+func TestParsingColors(t *testing.T) {
+	parsed, error := ParseColor("#12ab0f")
+	if error != nil || parsed.R != 0x12 || parsed.G != 0xab || parsed.B != 0x0f || parsed.A != 0xff {
+		t.Errorf("Parsed %v, %v", parsed, error)
+	}
+	for _, spec := range []string{"", "#12ab0", "#12ab0fa", "red", "#12ag0f", "##12ab0f"} {
+		if _, error := ParseColor(spec); error == nil {
+			t.Errorf("Invalid colour %q was parsed", spec)
+		}
+	}
+}
+
+func TestPaintingSolution(t *testing.T) {
+	b := smallBoard()
+	path, _ := b.Solve()
+	lineColor := image.RGBAColor{0x12, 0x34, 0x56, 0xff}
+	style := DefaultStyle
+	style.PathColor, style.PathWidth = lineColor, 2
+	img := style.Paint(b, nil, path)
+	isColor := func(x, y int, expected image.RGBAColor) bool {
+		r, g, b, _ := img.At(x, y).RGBA()
+		return r>>8 == uint32(expected.R) && g>>8 == uint32(expected.G) &&
+			b>>8 == uint32(expected.B)
+	}
+	// The path leads from the entrance at (0, 0) down to (0, 1) and right
+	// to the exit at (1, 1), crossing the gaps in the walls between them.
+	for y := 9; y <= 16; y++ {
+		if !isColor(6, y, lineColor) {
+			t.Errorf("Pixel (6, %d) is not on the path", y)
+		}
+	}
+	for x := 9; x <= 13; x++ {
+		if !isColor(x, 16, lineColor) {
+			t.Errorf("Pixel (%d, 16) is not on the path", x)
+		}
+	}
+	if isColor(3, 16, lineColor) || isColor(16, 6, lineColor) {
+		t.Errorf("Path is wider than 2 pixels")
+	}
+	if !isColor(6, 6, entranceColor) || !isColor(16, 16, exitColor) {
+		t.Errorf("Entrance and exit are not marked")
+	}
+}
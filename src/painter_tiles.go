@@ -0,0 +1,146 @@
+package painter
+
+import (
+	"board"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path"
+)
+
+// bandRows is the number of rows of fields rendered at once by WritePNG.
+const bandRows = 16
+
+// noFill leaves all the fields empty.
+func noFill(x, y int) (image.RGBAColor, bool) {
+	return image.RGBAColor{}, false
+}
+
+// WritePNG renders the board like Paint does and encodes it as PNG, but only
+// keeps a band of a few rows of fields in memory at a time, so that pictures
+// of boards far larger than the available memory can be written.
+func (self Style) WritePNG(w io.Writer, b board.Board, path board.Path) os.Error {
+	size := self.imageSize(b)
+	out, error := newPNGWriter(w, size.X, size.Y)
+	if error != nil {
+		return error
+	}
+	bandHeight := bandRows * self.CellSize
+	for top := 0; top < size.Y; top += bandHeight {
+		band := image.Rect(0, top, size.X, min(top+bandHeight, size.Y))
+		img := self.paintRegion(b, band, noFill, false, path)
+		for y := 0; y < band.Dy(); y++ {
+			if error = out.WriteRow(img.Pix[y*img.Stride:]); error != nil {
+				return error
+			}
+		}
+	}
+	return out.Close()
+}
+
+// WriteTiles renders the board as a pyramid of square PNG tiles, stored as
+// z/x/y.png in the directory, as used by web map viewers. At the zoom level 0,
+// the whole picture is scaled down to fit in a single tile; each next level
+// doubles the scale, up to the last one showing the picture in full size.
+// Tiles are rendered depth first, so that only a few of them are kept in
+// memory at a time.
+func (self Style) WriteTiles(dir string, b board.Board, path board.Path, tileSize int) os.Error {
+	if tileSize < 1 {
+		return fmt.Errorf("Invalid tile size %d", tileSize)
+	}
+	size := self.imageSize(b)
+	maxZoom := 0
+	for tileSize<<uint(maxZoom) < size.X || tileSize<<uint(maxZoom) < size.Y {
+		maxZoom++
+	}
+	pyramid := &tilePyramid{
+		style:    self,
+		board:    b,
+		path:     path,
+		dir:      dir,
+		tileSize: tileSize,
+		maxZoom:  maxZoom,
+		bounds:   image.Rect(0, 0, size.X, size.Y),
+	}
+	_, error := pyramid.tile(0, 0, 0)
+	return error
+}
+
+type tilePyramid struct {
+	style             Style
+	board             board.Board
+	path              board.Path
+	dir               string
+	tileSize, maxZoom int
+	bounds            image.Rectangle
+}
+
+// tile renders and writes the tile and all the tiles below it. It returns
+// nil for tiles outside of the picture, which are not written.
+func (self *tilePyramid) tile(zoom, x, y int) (*image.RGBA, os.Error) {
+	scale := self.tileSize << uint(self.maxZoom-zoom)
+	region := image.Rect(x*scale, y*scale, (x+1)*scale, (y+1)*scale)
+	if !region.Overlaps(self.bounds) {
+		return nil, nil
+	}
+
+	var img *image.RGBA
+	if zoom == self.maxZoom {
+		img = self.style.paintRegion(self.board, region, noFill, false, self.path)
+	} else {
+		// The four tiles below are put together before scaling them down, as
+		// with an odd tile size, pixels of this tile straddle their borders.
+		size := 2 * self.tileSize
+		children := image.NewRGBA(size, size)
+		for i := 0; i < 4; i++ {
+			child, error := self.tile(zoom+1, 2*x+i%2, 2*y+i/2)
+			if error != nil {
+				return nil, error
+			}
+			if child != nil {
+				at := image.Pt(i%2*self.tileSize, i/2*self.tileSize)
+				for row := 0; row < self.tileSize; row++ {
+					copy(children.Pix[(at.Y+row)*children.Stride+4*at.X:],
+						child.Pix[row*child.Stride:row*child.Stride+4*self.tileSize])
+				}
+			}
+		}
+		img = image.NewRGBA(self.tileSize, self.tileSize)
+		downsample(img, children)
+	}
+
+	dir := path.Join(self.dir, fmt.Sprint(zoom), fmt.Sprint(x))
+	if error := os.MkdirAll(dir, 0755); error != nil {
+		return nil, error
+	}
+	file, error := os.Create(path.Join(dir, fmt.Sprintf("%d.png", y)))
+	if error != nil {
+		return nil, error
+	}
+	defer file.Close()
+	if error = png.Encode(file, img); error != nil {
+		return nil, error
+	}
+	return img, nil
+}
+
+// downsample draws the source at half its size, averaging blocks of four
+// pixels.
+func downsample(dst *image.RGBA, src *image.RGBA) {
+	for y := 0; y < src.Bounds().Dy()/2; y++ {
+		for x := 0; x < src.Bounds().Dx()/2; x++ {
+			var r, g, b, a int
+			for _, p := range []image.Point{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+				i := (2*y+p.Y)*src.Stride + 4*(2*x+p.X)
+				r += int(src.Pix[i])
+				g += int(src.Pix[i+1])
+				b += int(src.Pix[i+2])
+				a += int(src.Pix[i+3])
+			}
+			dst.SetRGBA(x, y,
+				image.RGBAColor{uint8(r / 4), uint8(g / 4), uint8(b / 4), uint8(a / 4)})
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package painter
+
+import (
+	"bytes"
+	"fmt"
+	"generator"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path"
+	"rand"
+	"testing"
+)
+
+func tilesTestStyle() Style {
+	style := DefaultStyle
+	style.Margin = 3
+	style.RoundCaps = true
+	return style
+}
+
+func samePixels(img1, img2 image.Image, offset image.Point, rect image.Rectangle) bool {
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			r1, g1, b1, a1 := img1.At(x, y).RGBA()
+			r2, g2, b2, a2 := img2.At(x+offset.X, y+offset.Y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestWritingPNGInBands(t *testing.T) {
+	b := generator.Generate(40, 25, rand.New(rand.NewSource(0)))
+	solution, _ := b.Solve()
+	style := tilesTestStyle()
+	var buf bytes.Buffer
+	if error := style.WritePNG(&buf, b, solution); error != nil {
+		t.Fatalf("Unable to write the image: %v", error)
+	}
+	written, error := png.Decode(&buf)
+	if error != nil {
+		t.Fatalf("Unable to decode the written image: %v", error)
+	}
+	painted := style.Paint(b, nil, solution)
+	if !written.Bounds().Eq(painted.Bounds()) {
+		t.Fatalf("Written image bounds are %v, expected %v",
+			written.Bounds(), painted.Bounds())
+	}
+	if !samePixels(written, painted, image.ZP, painted.Bounds()) {
+		t.Errorf("Written image differs from the painted one")
+	}
+}
+
+func TestWritingTranslucentPNG(t *testing.T) {
+	b := generator.Generate(5, 4, rand.New(rand.NewSource(0)))
+	style := DefaultStyle
+	if error := style.Parse("board-color=#ffffff80,wall-color=#ff000040"); error != nil {
+		t.Fatalf("Unable to parse the style: %v", error)
+	}
+	var written, encoded bytes.Buffer
+	if error := style.WritePNG(&written, b, nil); error != nil {
+		t.Fatalf("Unable to write the image: %v", error)
+	}
+	if error := png.Encode(&encoded, style.Paint(b, nil, nil)); error != nil {
+		t.Fatalf("Unable to encode the image: %v", error)
+	}
+	img1, error := png.Decode(&written)
+	if error != nil {
+		t.Fatalf("Unable to decode the written image: %v", error)
+	}
+	img2, error := png.Decode(&encoded)
+	if error != nil {
+		t.Fatalf("Unable to decode the encoded image: %v", error)
+	}
+	if !img1.Bounds().Eq(img2.Bounds()) || !samePixels(img1, img2, image.ZP, img2.Bounds()) {
+		t.Errorf("Written image differs from the one encoded by png.Encode")
+	}
+}
+
+func TestWritingTiles(t *testing.T) {
+	dir, error := ioutil.TempDir("", "painter")
+	if error != nil {
+		t.Fatalf("Unable to create a temporary directory: %v", error)
+	}
+	defer os.RemoveAll(dir)
+	b := generator.Generate(40, 24, rand.New(rand.NewSource(0)))
+	style := tilesTestStyle()
+	const tileSize = 64
+	if error := style.WriteTiles(dir, b, nil, tileSize); error != nil {
+		t.Fatalf("Unable to write the tiles: %v", error)
+	}
+	painted := style.Paint(b, nil, nil)
+	// The picture is 408x248 pixels, so it takes three zoom levels to get
+	// from a single tile to the full size.
+	for _, test := range []struct {
+		Zoom, X, Y int
+		Exists     bool
+	}{
+		{0, 0, 0, true}, {1, 1, 0, true}, {1, 0, 1, false},
+		{3, 6, 3, true}, {3, 7, 0, false}, {3, 0, 4, false},
+	} {
+		tileName := path.Join(dir, fmt.Sprint(test.Zoom), fmt.Sprint(test.X),
+			fmt.Sprintf("%d.png", test.Y))
+		file, error := os.Open(tileName)
+		if !test.Exists {
+			if error == nil {
+				file.Close()
+				t.Errorf("Tile %s outside of the picture was written", tileName)
+			}
+			continue
+		}
+		if error != nil {
+			t.Fatalf("Tile %s is missing: %v", tileName, error)
+		}
+		tile, error := png.Decode(file)
+		file.Close()
+		if error != nil {
+			t.Fatalf("Unable to decode tile %s: %v", tileName, error)
+		}
+		if !tile.Bounds().Eq(image.Rect(0, 0, tileSize, tileSize)) {
+			t.Errorf("Tile %s has bounds %v", tileName, tile.Bounds())
+		}
+		if test.Zoom == 3 {
+			offset := image.Pt(test.X*tileSize, test.Y*tileSize)
+			visible := painted.Bounds().Sub(offset).Intersect(tile.Bounds())
+			if !samePixels(tile, painted, offset, visible) {
+				t.Errorf("Tile %s differs from the painted image", tileName)
+			}
+		}
+	}
+}
+
+func TestWritingOddSizedTiles(t *testing.T) {
+	dir, error := ioutil.TempDir("", "painter")
+	if error != nil {
+		t.Fatalf("Unable to create a temporary directory: %v", error)
+	}
+	defer os.RemoveAll(dir)
+	b := generator.Generate(40, 24, rand.New(rand.NewSource(0)))
+	if error := tilesTestStyle().WriteTiles(dir, b, nil, 63); error != nil {
+		t.Fatalf("Unable to write the tiles: %v", error)
+	}
+	// The 408x248 picture takes zoom levels up to 3. The first tile of level
+	// 2 lies within the picture and the one of level 0 covers it at an
+	// eighth of its size, so all their pixels there are painted.
+	for _, test := range []struct {
+		Zoom    int
+		Painted image.Rectangle
+	}{
+		{2, image.Rect(0, 0, 63, 63)},
+		{0, image.Rect(0, 0, 51, 31)},
+	} {
+		tileName := path.Join(dir, fmt.Sprint(test.Zoom), "0", "0.png")
+		file, error := os.Open(tileName)
+		if error != nil {
+			t.Fatalf("Tile %s is missing: %v", tileName, error)
+		}
+		tile, error := png.Decode(file)
+		file.Close()
+		if error != nil {
+			t.Fatalf("Unable to decode tile %s: %v", tileName, error)
+		}
+		for y := test.Painted.Min.Y; y < test.Painted.Max.Y; y++ {
+			for x := test.Painted.Min.X; x < test.Painted.Max.X; x++ {
+				if _, _, _, a := tile.At(x, y).RGBA(); a != 0xffff {
+					t.Fatalf("Pixel (%d, %d) of tile %s is not painted", x, y, tileName)
+				}
+			}
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package testutil
 
+import (
+	"image"
+	"math"
+)
+
 func MatricesEqual(m1, m2 [][]bool) bool {
 	if len(m1) != len(m2) {
 		return false
@@ -17,3 +22,139 @@ func MatricesEqual(m1, m2 [][]bool) bool {
 	}
 	return true
 }
+
+// GridEdge is an edge of a grid graph, connecting a field with its eastern or
+// southern neighbour.
+type GridEdge struct {
+	From, To image.Point
+}
+
+func GridEdges(width, height int) []GridEdge {
+	edges := make([]GridEdge, 0)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x+1 < width {
+				edges = append(edges, GridEdge{image.Pt(x, y), image.Pt(x+1, y)})
+			}
+			if y+1 < height {
+				edges = append(edges, GridEdge{image.Pt(x, y), image.Pt(x, y+1)})
+			}
+		}
+	}
+	return edges
+}
+
+// SpanningTrees enumerates all spanning trees of a tiny grid graph by brute
+// force. Each tree is a bit mask of edges, indexed as in GridEdges.
+func SpanningTrees(width, height int) []uint64 {
+	edges := GridEdges(width, height)
+	fields := width * height
+	trees := make([]uint64, 0)
+	for mask := uint64(0); mask < uint64(1)<<uint(len(edges)); mask++ {
+		if bitCount(mask) != fields-1 {
+			continue
+		}
+		sets := make([]int, fields)
+		for i := range sets {
+			sets[i] = i
+		}
+		find := func(i int) int {
+			for sets[i] != i {
+				i = sets[i]
+			}
+			return i
+		}
+		acyclic := true
+		for i, edge := range edges {
+			if mask&(1<<uint(i)) == 0 {
+				continue
+			}
+			from := find(edge.From.Y*width + edge.From.X)
+			to := find(edge.To.Y*width + edge.To.X)
+			if from == to {
+				acyclic = false
+				break
+			}
+			sets[to] = from
+		}
+		// An acyclic graph with fields-1 edges is a spanning tree.
+		if acyclic {
+			trees = append(trees, mask)
+		}
+	}
+	return trees
+}
+
+func bitCount(mask uint64) int {
+	count := 0
+	for ; mask != 0; mask &= mask - 1 {
+		count++
+	}
+	return count
+}
+
+// ChiSquareUniformity performs Pearson's chi-square test of the hypothesis
+// that the counts come from a uniform distribution, and returns its p-value.
+func ChiSquareUniformity(counts []int) float64 {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	expected := float64(total) / float64(len(counts))
+	statistic := 0.0
+	for _, count := range counts {
+		diff := float64(count) - expected
+		statistic += diff * diff / expected
+	}
+	return ChiSquarePValue(statistic, len(counts)-1)
+}
+
+// ChiSquarePValue returns the probability that a chi-square distributed
+// variable with the given degrees of freedom exceeds the statistic.
+func ChiSquarePValue(statistic float64, degreesOfFreedom int) float64 {
+	return upperIncompleteGamma(float64(degreesOfFreedom)/2, statistic/2)
+}
+
+// upperIncompleteGamma computes the regularized upper incomplete gamma
+// function Q(a, x), using a series for small x and a continued fraction
+// otherwise (see Numerical Recipes, chapter 6.2).
+func upperIncompleteGamma(a, x float64) float64 {
+	const epsilon, tiny = 1e-15, 1e-300
+	if x <= 0 {
+		return 1
+	}
+	lgamma, _ := math.Lgamma(a)
+	scale := math.Exp(-x + a*math.Log(x) - lgamma)
+	if x < a+1 {
+		term := 1 / a
+		sum := term
+		for n := 1.0; math.Fabs(term) > math.Fabs(sum)*epsilon; n++ {
+			term *= x / (a + n)
+			sum += term
+		}
+		return 1 - sum*scale
+	}
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1.0; ; i++ {
+		an := -i * (i - a)
+		b += 2
+		d = an*d + b
+		if math.Fabs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Fabs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Fabs(delta-1) < epsilon {
+			break
+		}
+	}
+	return h * scale
+}
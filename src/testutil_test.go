@@ -4,6 +4,7 @@
 package testutil
 
 import (
+	"math"
 	"testing"
 )
 
@@ -72,3 +73,49 @@ func TestMatrixEuqality(t *testing.T) {
 		}
 	}
 }
+
+func TestSpanningTreeCounts(t *testing.T) {
+	testCases := []struct {
+		Width, Height, Count int
+	}{
+		{1, 1, 1}, {1, 4, 1}, {2, 2, 4}, {2, 3, 15}, {3, 3, 192},
+	}
+	for _, test := range testCases {
+		count := len(SpanningTrees(test.Width, test.Height))
+		if count != test.Count {
+			t.Errorf("Number of spanning trees of a %dx%d grid is %d, expected %d",
+				test.Width, test.Height, count, test.Count)
+		}
+	}
+}
+
+func TestChiSquarePValue(t *testing.T) {
+	testCases := []struct {
+		Statistic        float64
+		DegreesOfFreedom int
+		PValue           float64
+	}{
+		{0, 3, 1},
+		{3.841, 1, 0.05},
+		{18.307, 10, 0.05},
+		{23.209, 10, 0.01},
+		{2.706, 1, 0.1},
+		{191, 191, 0.4861},
+	}
+	for _, test := range testCases {
+		p := ChiSquarePValue(test.Statistic, test.DegreesOfFreedom)
+		if math.Fabs(p-test.PValue) > 1e-3 {
+			t.Errorf("P-value of %v with %d degrees of freedom is %v, expected %v",
+				test.Statistic, test.DegreesOfFreedom, p, test.PValue)
+		}
+	}
+}
+
+func TestChiSquareUniformity(t *testing.T) {
+	if p := ChiSquareUniformity([]int{100, 100, 100, 100}); p != 1 {
+		t.Errorf("P-value of uniform counts is %v, expected 1", p)
+	}
+	if p := ChiSquareUniformity([]int{10, 100, 100, 190}); p > 1e-6 {
+		t.Errorf("P-value of skewed counts is %v, expected almost 0", p)
+	}
+}